@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jeffreyyong/xe/backtest"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/config"
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/jeffreyyong/xe/server"
+)
+
+const (
+	// allStrategies backtests every registered strategy
+	allStrategies = "all"
+
+	// daysForBacktest is the number of days
+	// backtested when start is not provided
+	daysForBacktest = 365
+)
+
+// backtestCmd replays historical rates through the strategies
+// and prints how following their advice would have done, e.g.
+// xe backtest -currency USD -strategy all -window 30. The
+// provider and strategies are those of the config file and
+// XE_ environment variables of the service, the strategy
+// flags override them.
+func backtestCmd(args []string) {
+	fs := flag.NewFlagSet(cmdBacktest, flag.ExitOnError)
+	configFile := fs.String(config.FlagConfig, "", "YAML or JSON config file of the service, "+
+		config.EnvPrefix+" environment variables override it")
+	currency := fs.String("currency", "", "currency converted to EUR, e.g. USD")
+	strategy := fs.String("strategy", calculator.StrategyTrend,
+		"strategy to backtest, or 'all' for every strategy")
+	ratesFile := fs.String("rates", "",
+		"file of stored historical rates in the exchangeratesapi history format, "+
+			"rates are fetched from exchangeratesapi if not provided")
+	start := fs.String("start", "", "first date of the backtest, YYYY-MM-DD")
+	end := fs.String("end", "", "last date of the backtest, YYYY-MM-DD, defaults to today")
+	trades := fs.Bool("trades", false, "print the report with every trade as JSON")
+
+	s := backtest.DefaultSchedule(server.DaysForRates)
+	fs.IntVar(&s.Window, "window", s.Window,
+		"number of days of historical rates for the recommendation")
+	fs.IntVar(&s.Every, "every", s.Every, "number of days between conversions")
+	fs.IntVar(&s.Horizon, "horizon", s.Horizon, "max number of days a conversion can wait")
+	fs.Float64Var(&s.Amount, "amount", s.Amount, "amount of currency of each conversion")
+	params := config.StrategyFlags(fs)
+	fs.Parse(args)
+
+	cfg, err := config.Load(*configFile, os.LookupEnv)
+	if err != nil {
+		log.Fatal("invalid config: ", err)
+	}
+	p, err := params(cfg.Strategies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	strategies, err := calculator.NewStrategies(p)
+	if err != nil {
+		log.Fatal("invalid strategy params: ", err)
+	}
+	backtested, err := strategiesToBacktest(strategies, *strategy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ratesList, err := loadRates(cfg, *ratesFile, *currency, *start, *end, s.Window)
+	if err != nil {
+		log.Fatal("error loading historical rates: ", err)
+	}
+
+	reports := make([]*backtest.Report, len(backtested))
+	for i, st := range backtested {
+		if reports[i], err = backtest.Run(st.Engine, ratesList, s); err != nil {
+			log.Fatalf("error backtesting %s: %v", st.Name, err)
+		}
+	}
+
+	if *trades {
+		printTrades(backtested, reports)
+		return
+	}
+	printReports(backtested, reports)
+}
+
+// strategiesToBacktest returns the strategy of the name,
+// or every strategy if the name is 'all'
+func strategiesToBacktest(strategies *calculator.Registry, name string) ([]calculator.Strategy, error) {
+	if name == allStrategies {
+		return strategies.List(), nil
+	}
+
+	st, ok := strategies.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy: %s", name)
+	}
+	return []calculator.Strategy{st}, nil
+}
+
+// loadRates reads the stored historical rates of the file, or
+// fetches the rates of the currency from the provider of the
+// config, between start and end including the window before start
+func loadRates(cfg *config.Config, file, currency, start, end string, window int) (model.RatesList, error) {
+	var startDate, endDate time.Time
+	if end != "" {
+		d, err := date.Parse(end)
+		if err != nil {
+			return nil, err
+		}
+		endDate = d
+	}
+	if start != "" {
+		d, err := date.Parse(start)
+		if err != nil {
+			return nil, err
+		}
+		startDate = d
+	}
+	if start != "" && end != "" && startDate.After(endDate) {
+		return nil, errors.New("start must not be after end")
+	}
+
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		ratesList, err := backtest.LoadRates(f)
+		if err != nil {
+			return nil, err
+		}
+
+		from := ""
+		if start != "" {
+			from, _ = date.GenerateStartAndEnd(startDate, window)
+		}
+		return backtest.Between(ratesList, from, end), nil
+	}
+
+	if currency == "" {
+		return nil, errors.New("currency or rates must be provided")
+	}
+
+	if end == "" {
+		endDate = time.Now()
+	}
+	if start == "" {
+		startDate = endDate.AddDate(0, 0, -daysForBacktest)
+	}
+
+	from, _ := date.GenerateStartAndEnd(startDate, window)
+	historicalRates, err := newForex(cfg, nil).GetHistoricalRates(currency, from, date.Format(endDate))
+	if err != nil {
+		return nil, err
+	}
+	return historicalRates.RatesList, nil
+}
+
+// printReports prints a summary of the reports as a table
+func printReports(strategies []calculator.Strategy, reports []*backtest.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STRATEGY\tCONVERSIONS\tHIT RATE\tAVG SAVINGS\tAVG SAVINGS %\tTOTAL SAVINGS\tMAX DRAWDOWN")
+	for i, st := range strategies {
+		r := reports[i]
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.4f\t%.4f\t%.4f\t%.4f\n", st.Name, r.Conversions,
+			r.HitRate, r.AverageSavings, r.AverageSavingsPercent, r.TotalSavings, r.MaxDrawdown)
+	}
+	w.Flush()
+}
+
+// printTrades prints the reports with
+// every trade as JSON by strategy
+func printTrades(strategies []calculator.Strategy, reports []*backtest.Report) {
+	byStrategy := map[string]*backtest.Report{}
+	for i, st := range strategies {
+		byStrategy[st.Name] = reports[i]
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(byStrategy); err != nil {
+		log.Fatal(err)
+	}
+}
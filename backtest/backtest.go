@@ -0,0 +1,269 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// DefaultEvery is the number of days on which a rate is
+	// published between two conversions of the schedule
+	DefaultEvery = 5
+
+	// DefaultHorizon is the max number of days on which a rate
+	// is published a conversion can wait for the advice
+	DefaultHorizon = 10
+
+	// DefaultAmount is the amount of currency of each conversion
+	DefaultAmount = 1000
+)
+
+var (
+	errInvalidSchedule = errors.New("every and window must be positive, horizon and amount must not be negative")
+	errNoConversions   = errors.New("not enough rates for a conversion")
+)
+
+// Schedule describes when conversions are due and
+// how long they can wait for the advice of the engine.
+// Days are days on which a rate is published.
+type Schedule struct {
+	// Every is the number of days between two conversions
+	Every int
+
+	// Horizon is the max number of days a conversion
+	// can wait, it is converted on the last day regardless
+	Horizon int
+
+	// Amount is the amount of currency of each conversion
+	Amount float64
+
+	// Window is the number of calendar days of historical
+	// rates the engine is given on each day
+	Window int
+}
+
+// DefaultSchedule returns the default Schedule
+// with the given lookback window
+func DefaultSchedule(window int) Schedule {
+	return Schedule{
+		Every:   DefaultEvery,
+		Horizon: DefaultHorizon,
+		Amount:  DefaultAmount,
+		Window:  window,
+	}
+}
+
+// Validate checks the schedule can be simulated
+func (s Schedule) Validate() error {
+	if s.Every < 1 || s.Window < 1 || s.Horizon < 0 || s.Amount < 0 {
+		return errInvalidSchedule
+	}
+	return nil
+}
+
+// Trade is a simulated conversion
+type Trade struct {
+	// Due is the date the conversion is due,
+	// it would be converted immediately on this date
+	Due string `json:"due"`
+
+	// Converted is the date the engine advised to convert,
+	// or the last day of the horizon
+	Converted string `json:"converted"`
+
+	ImmediateRate float64 `json:"immediate_rate"`
+	Rate          float64 `json:"rate"`
+
+	// Savings is the amount in EUR gained by following
+	// the advice instead of converting immediately
+	Savings float64 `json:"savings"`
+}
+
+// Report is the result of a backtest
+type Report struct {
+	Conversions int `json:"conversions"`
+
+	// Hits and Misses are the conversions where following the
+	// advice was better and worse than converting immediately,
+	// conversions converted immediately are neither
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+
+	// AverageSavings is the average amount in EUR gained per
+	// conversion, AverageSavingsPercent relative to converting
+	// immediately
+	AverageSavings        float64 `json:"average_savings"`
+	AverageSavingsPercent float64 `json:"average_savings_percent"`
+	TotalSavings          float64 `json:"total_savings"`
+
+	// MaxDrawdown is the largest fall in EUR of the
+	// total savings from a previous peak
+	MaxDrawdown float64 `json:"max_drawdown"`
+
+	Trades []Trade `json:"trades"`
+}
+
+// LoadRates decodes historical rates stored in the format
+// of the exchangeratesapi history response
+func LoadRates(r io.Reader) (model.RatesList, error) {
+	historicalRates := &model.HistoricalRates{}
+	if err := json.NewDecoder(r).Decode(historicalRates); err != nil {
+		return nil, err
+	}
+	return historicalRates.RatesList, nil
+}
+
+// Between returns the rates published from start to end,
+// both YYYY-MM-DD and included, an empty start or end
+// leaves the period open on that side
+func Between(ratesList model.RatesList, start, end string) model.RatesList {
+	between := model.RatesList{}
+	for d, rates := range ratesList {
+		if (start == "" || d >= start) && (end == "" || d <= end) {
+			between[d] = rates
+		}
+	}
+	return between
+}
+
+// Run replays the rates day by day through the engine and
+// simulates following its advice for the schedule
+// 1) a conversion is due every s.Every days, starting on the
+//    first day with s.Window days of history before it
+// 2) on every day from the due day, the engine is given the
+//    rates of the window ending on that day, the conversion
+//    is made on the first day the engine recommends 'convert'
+//    or on the last day of the horizon
+// 3) the conversion is compared with converting immediately
+//    on the due day
+// Conversions due too late for a full horizon are left out.
+// Insufficient data is treated as advice to wait,
+// other errors of the engine stop the backtest.
+func Run(e calculator.Engine, ratesList model.RatesList, s Schedule) (*Report, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	series := calculator.Series(ratesList, calculator.EUR)
+	if len(series) == 0 {
+		return nil, errNoConversions
+	}
+
+	first, err := firstDue(series, s.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for due := first; due+s.Horizon < len(series); due += s.Every {
+		converted, err := convertDay(e, series, due, s)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Trades = append(report.Trades, Trade{
+			Due:           series[due].Date,
+			Converted:     series[converted].Date,
+			ImmediateRate: series[due].Rate,
+			Rate:          series[converted].Rate,
+			Savings:       s.Amount * (series[converted].Rate - series[due].Rate),
+		})
+	}
+
+	if len(report.Trades) == 0 {
+		return nil, errNoConversions
+	}
+	summarise(report)
+	return report, nil
+}
+
+// firstDue returns the index of the first day
+// with window days of history before it
+func firstDue(series []model.RatePoint, window int) (int, error) {
+	start, err := date.Parse(series[0].Date)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, p := range series {
+		d, err := date.Parse(p.Date)
+		if err != nil {
+			return 0, err
+		}
+		if !d.AddDate(0, 0, -window).Before(start) {
+			return i, nil
+		}
+	}
+	return 0, errNoConversions
+}
+
+// convertDay returns the index of the day the
+// conversion due on the due day is made
+func convertDay(e calculator.Engine, series []model.RatePoint, due int, s Schedule) (int, error) {
+	last := due + s.Horizon
+	for i := due; i < last; i++ {
+		d, err := date.Parse(series[i].Date)
+		if err != nil {
+			return 0, err
+		}
+
+		start, end := date.GenerateStartAndEnd(d, s.Window)
+		recommendation, err := e.Recommend(windowOf(series, start, end))
+		if err == calculator.ErrInsufficientData {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if recommendation.Signal == calculator.SignalConvert {
+			return i, nil
+		}
+	}
+	return last, nil
+}
+
+// windowOf returns the rates between the
+// start and end dates inclusive
+func windowOf(series []model.RatePoint, start, end string) model.RatesList {
+	ratesList := model.RatesList{}
+	for _, p := range series {
+		if p.Date >= start && p.Date <= end {
+			ratesList[p.Date] = model.Rates{calculator.EUR: p.Rate}
+		}
+	}
+	return ratesList
+}
+
+// summarise computes the hit rate, savings
+// and drawdown of the trades of the report
+func summarise(report *Report) {
+	var percent, peak float64
+	for _, t := range report.Trades {
+		switch {
+		case t.Savings > 0:
+			report.Hits++
+		case t.Savings < 0:
+			report.Misses++
+		}
+
+		report.TotalSavings += t.Savings
+		percent += (t.Rate - t.ImmediateRate) / t.ImmediateRate * 100
+
+		peak = math.Max(peak, report.TotalSavings)
+		report.MaxDrawdown = math.Max(report.MaxDrawdown, peak-report.TotalSavings)
+	}
+
+	report.Conversions = len(report.Trades)
+	if decided := report.Hits + report.Misses; decided > 0 {
+		report.HitRate = float64(report.Hits) / float64(decided)
+	}
+	report.AverageSavings = report.TotalSavings / float64(report.Conversions)
+	report.AverageSavingsPercent = percent / float64(report.Conversions)
+}
@@ -0,0 +1,264 @@
+package backtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubEngine always returns the same recommendation
+// and error, and records the rates it is given
+type stubEngine struct {
+	signal calculator.Signal
+	err    error
+	given  []model.RatesList
+}
+
+func (e *stubEngine) Recommend(ratesList model.RatesList) (calculator.Recommendation, error) {
+	e.given = append(e.given, ratesList)
+	return calculator.Recommendation{Signal: e.signal}, e.err
+}
+
+// TestRun checks the advice of the engine is
+// followed and compared with converting immediately
+// Scenario:
+// 	- explained in the descriptions of tests
+// 	- conversions are due on the 3rd and 6th day
+// 	  and can wait 2 days
+//
+// Expect:
+// 	- right report is given
+func TestRun(t *testing.T) {
+	type testParams struct {
+		description string
+		rates       []float64
+		engine      *stubEngine
+		expReport   Report
+		expTrades   []Trade
+	}
+
+	rising := []float64{1.0, 1.1, 1.2, 1.3, 1.4, 1.5, 1.6, 1.7, 1.8, 1.9}
+	falling := []float64{2.0, 1.9, 1.8, 1.7, 1.6, 1.5, 1.4, 1.3, 1.2, 1.1}
+
+	cases := []testParams{
+		{
+			description: "waiting while the rate rises is a hit",
+			rates:       rising,
+			engine:      &stubEngine{signal: calculator.SignalNoConvert},
+			expReport: Report{
+				Conversions:           2,
+				Hits:                  2,
+				HitRate:               1,
+				AverageSavings:        20,
+				AverageSavingsPercent: 15,
+				TotalSavings:          40,
+			},
+			expTrades: []Trade{
+				{Due: "2019-11-03", Converted: "2019-11-05", ImmediateRate: 1.2, Rate: 1.4, Savings: 20},
+				{Due: "2019-11-06", Converted: "2019-11-08", ImmediateRate: 1.5, Rate: 1.7, Savings: 20},
+			},
+		},
+		{
+			description: "waiting while the rate falls is a miss with drawdown",
+			rates:       falling,
+			engine:      &stubEngine{signal: calculator.SignalNoConvert},
+			expReport: Report{
+				Conversions:           2,
+				Misses:                2,
+				AverageSavings:        -20,
+				AverageSavingsPercent: -12.2222,
+				TotalSavings:          -40,
+				MaxDrawdown:           40,
+			},
+			expTrades: []Trade{
+				{Due: "2019-11-03", Converted: "2019-11-05", ImmediateRate: 1.8, Rate: 1.6, Savings: -20},
+				{Due: "2019-11-06", Converted: "2019-11-08", ImmediateRate: 1.5, Rate: 1.3, Savings: -20},
+			},
+		},
+		{
+			description: "converting immediately is neither a hit nor a miss",
+			rates:       rising,
+			engine:      &stubEngine{signal: calculator.SignalConvert},
+			expReport: Report{
+				Conversions: 2,
+			},
+			expTrades: []Trade{
+				{Due: "2019-11-03", Converted: "2019-11-03", ImmediateRate: 1.2, Rate: 1.2},
+				{Due: "2019-11-06", Converted: "2019-11-06", ImmediateRate: 1.5, Rate: 1.5},
+			},
+		},
+		{
+			description: "insufficient data is advice to wait",
+			rates:       rising,
+			engine:      &stubEngine{err: calculator.ErrInsufficientData},
+			expReport: Report{
+				Conversions:           2,
+				Hits:                  2,
+				HitRate:               1,
+				AverageSavings:        20,
+				AverageSavingsPercent: 15,
+				TotalSavings:          40,
+			},
+			expTrades: []Trade{
+				{Due: "2019-11-03", Converted: "2019-11-05", ImmediateRate: 1.2, Rate: 1.4, Savings: 20},
+				{Due: "2019-11-06", Converted: "2019-11-08", ImmediateRate: 1.5, Rate: 1.7, Savings: 20},
+			},
+		},
+	}
+
+	s := Schedule{Every: 3, Horizon: 2, Amount: 100, Window: 2}
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			report, err := Run(tt.engine, ratesListOf(tt.rates...), s)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expReport.Conversions, report.Conversions, "conversions are wrong")
+			assert.Equal(t, tt.expReport.Hits, report.Hits, "hits are wrong")
+			assert.Equal(t, tt.expReport.Misses, report.Misses, "misses are wrong")
+			assert.Equal(t, tt.expReport.HitRate, report.HitRate, "hit rate is wrong")
+			assert.InDelta(t, tt.expReport.AverageSavings, report.AverageSavings, 1e-4, "average savings are wrong")
+			assert.InDelta(t, tt.expReport.AverageSavingsPercent, report.AverageSavingsPercent, 1e-4,
+				"average savings percent is wrong")
+			assert.InDelta(t, tt.expReport.TotalSavings, report.TotalSavings, 1e-4, "total savings are wrong")
+			assert.InDelta(t, tt.expReport.MaxDrawdown, report.MaxDrawdown, 1e-4, "max drawdown is wrong")
+
+			assert.Len(t, report.Trades, len(tt.expTrades))
+			for i, trade := range tt.expTrades {
+				assert.Equal(t, trade.Due, report.Trades[i].Due, "due date is wrong")
+				assert.Equal(t, trade.Converted, report.Trades[i].Converted, "converted date is wrong")
+				assert.InDelta(t, trade.ImmediateRate, report.Trades[i].ImmediateRate, 1e-9)
+				assert.InDelta(t, trade.Rate, report.Trades[i].Rate, 1e-9)
+				assert.InDelta(t, trade.Savings, report.Trades[i].Savings, 1e-9)
+			}
+		})
+	}
+}
+
+// TestRunWindow checks the engine is only
+// given the rates of the window
+// Scenario:
+// 	- window of 2 days
+//
+// Expect:
+// 	- engine is given the rate of the day
+// 	  and of the 2 days before it
+func TestRunWindow(t *testing.T) {
+	e := &stubEngine{signal: calculator.SignalConvert}
+	_, err := Run(e, ratesListOf(1.0, 1.1, 1.2, 1.3, 1.4), Schedule{Every: 1, Horizon: 1, Window: 2})
+	assert.NoError(t, err)
+
+	expGiven := []model.RatesList{
+		{
+			"2019-11-01": {calculator.EUR: 1.0},
+			"2019-11-02": {calculator.EUR: 1.1},
+			"2019-11-03": {calculator.EUR: 1.2},
+		},
+		{
+			"2019-11-02": {calculator.EUR: 1.1},
+			"2019-11-03": {calculator.EUR: 1.2},
+			"2019-11-04": {calculator.EUR: 1.3},
+		},
+	}
+	assert.Equal(t, expGiven, e.given)
+}
+
+// TestRunError checks the backtest stops on errors
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- error is returned
+func TestRunError(t *testing.T) {
+	type testParams struct {
+		description string
+		engine      calculator.Engine
+		ratesList   model.RatesList
+		schedule    Schedule
+	}
+
+	cases := []testParams{
+		{
+			description: "engine errors",
+			engine:      &stubEngine{err: errors.New("invalid rate")},
+			ratesList:   ratesListOf(1.0, 1.1, 1.2, 1.3),
+			schedule:    Schedule{Every: 1, Horizon: 1, Window: 1},
+		},
+		{
+			description: "invalid schedule",
+			engine:      &stubEngine{},
+			ratesList:   ratesListOf(1.0, 1.1, 1.2, 1.3),
+			schedule:    Schedule{Every: 0, Horizon: 1, Window: 1},
+		},
+		{
+			description: "too few rates for the window",
+			engine:      &stubEngine{},
+			ratesList:   ratesListOf(1.0, 1.1),
+			schedule:    Schedule{Every: 1, Horizon: 0, Window: 5},
+		},
+		{
+			description: "too few rates for the horizon",
+			engine:      &stubEngine{},
+			ratesList:   ratesListOf(1.0, 1.1),
+			schedule:    Schedule{Every: 1, Horizon: 5, Window: 1},
+		},
+		{
+			description: "no rates",
+			engine:      &stubEngine{},
+			ratesList:   model.RatesList{},
+			schedule:    DefaultSchedule(7),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			_, err := Run(tt.engine, tt.ratesList, tt.schedule)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestLoadRates checks rates stored in the format
+// of the exchangeratesapi history response are decoded
+func TestLoadRates(t *testing.T) {
+	stored := `{"rates":{"2019-11-21":{"EUR":1.1689343994},"2019-11-22":{"EUR":1.163061177}},` +
+		`"base":"GBP","start_at":"2019-11-21","end_at":"2019-11-22"}`
+
+	ratesList, err := LoadRates(strings.NewReader(stored))
+	assert.NoError(t, err)
+	assert.Equal(t, model.RatesList{
+		"2019-11-21": {calculator.EUR: 1.1689343994},
+		"2019-11-22": {calculator.EUR: 1.163061177},
+	}, ratesList)
+
+	_, err = LoadRates(strings.NewReader("not json"))
+	assert.Error(t, err)
+}
+
+// TestBetween checks only the rates of the period are kept
+func TestBetween(t *testing.T) {
+	ratesList := ratesListOf(1, 2, 3, 4)
+
+	assert.Equal(t, model.RatesList{
+		"2019-11-02": {calculator.EUR: 2},
+		"2019-11-03": {calculator.EUR: 3},
+	}, Between(ratesList, "2019-11-02", "2019-11-03"))
+	assert.Equal(t, ratesListOf(1, 2), Between(ratesList, "", "2019-11-02"))
+	assert.Equal(t, ratesList, Between(ratesList, "", ""))
+}
+
+// ratesListOf builds a RatesList of EUR rates
+// on consecutive days from 2019-11-01
+func ratesListOf(rates ...float64) model.RatesList {
+	start := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	ratesList := model.RatesList{}
+	for i, r := range rates {
+		ratesList[date.Format(start.AddDate(0, 0, i))] = model.Rates{calculator.EUR: r}
+	}
+	return ratesList
+}
@@ -0,0 +1,185 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/model"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	// DefaultAROrder is the default number of
+	// previous rates each rate is regressed on
+	DefaultAROrder = 2
+
+	// epsilon is the machine epsilon of float64
+	epsilon = 1.0 / (1 << 52)
+)
+
+type arForecaster struct {
+	order      int
+	confidence float64
+}
+
+// NewARForecaster initialises the forecaster using an
+// autoregressive model of the given order, i.e. every
+// rate is a linear combination of the order rates before it
+func NewARForecaster(order int, confidence float64) (Forecaster, error) {
+	if order < 1 {
+		return nil, fmt.Errorf("order must be positive, got %d", order)
+	}
+	if err := validConfidence(confidence); err != nil {
+		return nil, err
+	}
+
+	return &arForecaster{
+		order:      order,
+		confidence: confidence,
+	}, nil
+}
+
+// MinRates returns 2 * order + 2, the rates
+// needed to fit the coefficients and the mean
+func (f *arForecaster) MinRates() int {
+	return 2*f.order + 2
+}
+
+// Forecast:
+// 1) fits y(t) = c + φ1 * y(t-1) + ... + φp * y(t-p) to the
+//    rates centred on their mean with least squares
+// 2) forecasts the rates one day at a time, feeding the
+//    forecast rates back into the model
+// 3) estimates the variance of the h days ahead forecast error
+//    from the residuals of the fit and the ψ weights of the
+//    model, σ² * (ψ0² + ... + ψ(h-1)²)
+// A series of constant rates is forecast to stay constant.
+// It returns ErrInsufficientData if there are fewer than
+// 2 * order + 2 rates.
+func (f *arForecaster) Forecast(series []model.RatePoint, days int) ([]Prediction, error) {
+	p := f.order
+	if len(series) < 2*p+2 {
+		return nil, ErrInsufficientData
+	}
+	rates := values(series)
+	last := series[len(series)-1].Date
+
+	mean, std := stat.MeanStdDev(rates, nil)
+	if std == 0 {
+		return predictionIntervals(last, constant(mean, days), make([]float64, days), f.confidence)
+	}
+
+	centred := make([]float64, len(rates))
+	for i, r := range rates {
+		centred[i] = r - mean
+	}
+
+	coef, variance, err := fitAR(centred, p)
+	if err != nil {
+		return nil, err
+	}
+
+	history := append([]float64{}, centred...)
+	forecast := make([]float64, days)
+	for i := range forecast {
+		y := coef[0]
+		for j := 1; j <= p; j++ {
+			y += coef[j] * history[len(history)-j]
+		}
+		history = append(history, y)
+		forecast[i] = y + mean
+	}
+
+	// ψ weights of the model, ψ0 = 1 and
+	// ψj = φ1 * ψ(j-1) + ... + φp * ψ(j-p)
+	psi := make([]float64, days)
+	variances := make([]float64, days)
+	sum := 0.0
+	for j := range psi {
+		psi[j] = 1
+		if j > 0 {
+			psi[j] = 0
+			for i := 1; i <= p && i <= j; i++ {
+				psi[j] += coef[i] * psi[j-i]
+			}
+		}
+		sum += psi[j] * psi[j]
+		variances[j] = variance * sum
+	}
+	return predictionIntervals(last, forecast, variances, f.confidence)
+}
+
+// fitAR fits the autoregressive model of order p to the
+// rates with least squares. It returns the intercept
+// followed by the p coefficients, and the variance of
+// the residuals. The lagged rates are collinear if the
+// rates are on a straight line, the minimum norm solution
+// is returned then, which continues the line.
+func fitAR(rates []float64, p int) ([]float64, float64, error) {
+	n := len(rates) - p
+	x := mat.NewDense(n, p+1, nil)
+	y := mat.NewVecDense(n, nil)
+	for t := 0; t < n; t++ {
+		x.Set(t, 0, 1)
+		for j := 1; j <= p; j++ {
+			x.Set(t, j, rates[p+t-j])
+		}
+		y.SetVec(t, rates[p+t])
+	}
+
+	coef, err := leastSquares(x, y)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var residuals mat.VecDense
+	residuals.MulVec(x, coef)
+	residuals.SubVec(y, &residuals)
+	variance := mat.Dot(&residuals, &residuals) / float64(n-p-1)
+
+	return mat.Col(nil, 0, coef), variance, nil
+}
+
+// leastSquares returns the minimum norm least squares
+// solution of x * coef = y with the pseudo-inverse of x,
+// which exists even if x is rank deficient
+func leastSquares(x *mat.Dense, y *mat.VecDense) (*mat.VecDense, error) {
+	var svd mat.SVD
+	if !svd.Factorize(x, mat.SVDThin) {
+		return nil, fmt.Errorf("least squares: SVD failed")
+	}
+
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	values := svd.Values(nil)
+
+	// singular values below the tolerance are treated as 0,
+	// i.e. the directions x is rank deficient in are dropped
+	r, c := x.Dims()
+	tolerance := values[0] * float64(r+c) * epsilon
+
+	// coef = V * Σ⁺ * Uᵀ * y
+	var uty mat.VecDense
+	uty.MulVec(u.T(), y)
+	for i, sv := range values {
+		if sv > tolerance {
+			uty.SetVec(i, uty.AtVec(i)/sv)
+		} else {
+			uty.SetVec(i, 0)
+		}
+	}
+
+	var coef mat.VecDense
+	coef.MulVec(&v, &uty)
+	return &coef, nil
+}
+
+// constant returns n times the rate
+func constant(rate float64, n int) []float64 {
+	rates := make([]float64, n)
+	for i := range rates {
+		rates[i] = rate
+	}
+	return rates
+}
@@ -0,0 +1,96 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestARForecast checks the forecast and prediction
+// intervals of the autoregressive model
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right predictions are given
+func TestARForecast(t *testing.T) {
+	type testParams struct {
+		description    string
+		order          int
+		rates          []float64
+		expPredictions []Prediction
+	}
+
+	cases := []testParams{
+		{
+			description: "noisy rates revert to the mean",
+			order:       1,
+			rates:       []float64{1.10, 1.12, 1.11, 1.13, 1.15, 1.14, 1.16, 1.15},
+			expPredictions: []Prediction{
+				{Date: "2019-11-11", Rate: 1.1485714285714284, Lower: 1.1204601682403807, Upper: 1.176682688902476},
+				{Date: "2019-11-12", Rate: 1.147755102040816, Lower: 1.1153779268381838, Upper: 1.1801322772434484},
+				{Date: "2019-11-13", Rate: 1.1472886297376093, Lower: 1.1136354137108546, Upper: 1.180941845764364},
+			},
+		},
+		{
+			description: "linear rates continue without error",
+			order:       1,
+			rates:       []float64{1.10, 1.11, 1.12, 1.13, 1.14, 1.15, 1.16, 1.17},
+			expPredictions: []Prediction{
+				{Date: "2019-11-11", Rate: 1.18, Lower: 1.18, Upper: 1.18},
+				{Date: "2019-11-12", Rate: 1.19, Lower: 1.19, Upper: 1.19},
+				{Date: "2019-11-13", Rate: 1.20, Lower: 1.20, Upper: 1.20},
+			},
+		},
+		{
+			description: "linear rates with collinear lags continue without error",
+			order:       2,
+			rates:       []float64{1.10, 1.11, 1.12, 1.13, 1.14, 1.15, 1.16, 1.17},
+			expPredictions: []Prediction{
+				{Date: "2019-11-11", Rate: 1.18, Lower: 1.18, Upper: 1.18},
+				{Date: "2019-11-12", Rate: 1.19, Lower: 1.19, Upper: 1.19},
+				{Date: "2019-11-13", Rate: 1.20, Lower: 1.20, Upper: 1.20},
+			},
+		},
+		{
+			description: "constant rates stay constant",
+			order:       2,
+			rates:       []float64{1.10, 1.10, 1.10, 1.10, 1.10, 1.10, 1.10, 1.10},
+			expPredictions: []Prediction{
+				{Date: "2019-11-11", Rate: 1.10, Lower: 1.10, Upper: 1.10},
+				{Date: "2019-11-12", Rate: 1.10, Lower: 1.10, Upper: 1.10},
+				{Date: "2019-11-13", Rate: 1.10, Lower: 1.10, Upper: 1.10},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			f, err := NewARForecaster(tt.order, DefaultConfidence)
+			assert.NoError(t, err)
+
+			predictions, err := f.Forecast(Series(ratesListOf(tt.rates...), EUR), 3)
+			assert.NoError(t, err)
+			assert.Len(t, predictions, len(tt.expPredictions))
+			for i, p := range tt.expPredictions {
+				assert.Equal(t, p.Date, predictions[i].Date, "date is wrong")
+				assert.InDelta(t, p.Rate, predictions[i].Rate, 1e-9, "rate is wrong")
+				assert.InDelta(t, p.Lower, predictions[i].Lower, 1e-6, "lower bound is wrong")
+				assert.InDelta(t, p.Upper, predictions[i].Upper, 1e-6, "upper bound is wrong")
+			}
+		})
+	}
+}
+
+// TestARForecastInsufficientData checks there must be
+// enough rates to fit the coefficients
+func TestARForecastInsufficientData(t *testing.T) {
+	f, err := NewARForecaster(2, DefaultConfidence)
+	assert.NoError(t, err)
+
+	_, err = f.Forecast(Series(ratesListOf(1.10, 1.11, 1.12, 1.13, 1.14), EUR), 3)
+	assert.Equal(t, ErrInsufficientData, err)
+
+	_, err = NewARForecaster(0, DefaultConfidence)
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// DefaultBollingerPeriod is the default number of
+	// rates in the moving average of the bands
+	DefaultBollingerPeriod = 20
+
+	// DefaultBollingerWidth is the default number of standard
+	// deviations between the moving average and the bands
+	DefaultBollingerWidth = 2.0
+)
+
+type bollingerEngine struct {
+	period int
+	width  float64
+}
+
+// NewBollingerEngine initialises the calculator engine that
+// recommends on where the latest rate sits in the Bollinger
+// bands of the rates
+func NewBollingerEngine(period int, width float64) (Engine, error) {
+	if period < 2 {
+		return nil, fmt.Errorf("period must be at least 2, got %d", period)
+	}
+	if width <= 0 {
+		return nil, fmt.Errorf("width must be positive, got %v", width)
+	}
+
+	return &bollingerEngine{
+		period: period,
+		width:  width,
+	}, nil
+}
+
+// Recommend:
+// 1) takes a list of rates and orders them by date
+// 2) computes the Bollinger bands of the latest period rates
+// 3) returns 'convert' if the latest rate is on or above the
+//    upper band, i.e. 1 currency buys unusually many euros,
+//    returns 'don't convert' if it is on or below the lower
+//    band, and 'neutral' otherwise or if the latest period
+//    rates are flat, as the bands collapse onto the mean then.
+// It returns ErrInsufficientData if there are fewer than
+// period rates.
+func (e *bollingerEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, e.period)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	rates := values(series)
+	if flat(rates[len(rates)-e.period:]) {
+		return Recommendation{Signal: SignalNeutral}, nil
+	}
+
+	lower, _, upper := bollingerBands(rates, e.period, e.width)
+	latest := rates[len(rates)-1]
+	if latest >= upper {
+		return Recommendation{Signal: SignalConvert}, nil
+	} else if latest <= lower {
+		return Recommendation{Signal: SignalNoConvert}, nil
+	}
+	return Recommendation{Signal: SignalNeutral}, nil
+}
+
+// bollingerBands computes the lower band, the simple moving
+// average and the upper band of the latest period rates.
+// The bands are width population standard deviations away
+// from the average. There must be at least period rates.
+func bollingerBands(rates []float64, period int, width float64) (float64, float64, float64) {
+	window := rates[len(rates)-period:]
+
+	mean := 0.0
+	for _, r := range window {
+		mean += r / float64(period)
+	}
+
+	variance := 0.0
+	for _, r := range window {
+		variance += (r - mean) * (r - mean) / float64(period)
+	}
+
+	std := math.Sqrt(variance)
+	return mean - width*std, mean, mean + width*std
+}
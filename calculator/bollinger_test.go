@@ -0,0 +1,90 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBollingerBands checks the bands are computed with
+// the population standard deviation of the latest rates
+// Scenario:
+// 	- given series with a known mean and standard deviation
+//
+// Expect:
+// 	- bands are width standard deviations away from the mean
+func TestBollingerBands(t *testing.T) {
+	// mean 5, population std 2
+	lower, middle, upper := bollingerBands([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	assert.InDelta(t, 1.0, lower, 1e-12)
+	assert.InDelta(t, 5.0, middle, 1e-12)
+	assert.InDelta(t, 9.0, upper, 1e-12)
+
+	// only the latest period rates are used, mean 3, population std sqrt(2)
+	lower, middle, upper = bollingerBands([]float64{100, 1, 2, 3, 4, 5}, 5, 1)
+	assert.InDelta(t, 1.5857864376269049, lower, 1e-12)
+	assert.InDelta(t, 3.0, middle, 1e-12)
+	assert.InDelta(t, 4.414213562373095, upper, 1e-12)
+}
+
+// TestBollingerRecommend checks that the right signal
+// recommendation is given based on the bands
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given
+func TestBollingerRecommend(t *testing.T) {
+	type testParams struct {
+		description       string
+		rates             []float64
+		expRecommendation Signal
+		expErr            error
+	}
+
+	cases := []testParams{
+		{
+			description:       "SignalConvert if latest rate is on the upper band",
+			rates:             []float64{1.02, 1.04, 1.04, 1.04, 1.05, 1.05, 1.07, 1.09},
+			expRecommendation: SignalConvert,
+		},
+		{
+			description:       "SignalNoConvert if latest rate is below the lower band",
+			rates:             []float64{1.05, 1.05, 1.06, 1.05, 1.06, 1.05, 1.06, 1.00},
+			expRecommendation: SignalNoConvert,
+		},
+		{
+			description:       "SignalNeutral if latest rate is within the bands",
+			rates:             []float64{1.05, 1.07, 1.05, 1.07, 1.05, 1.07, 1.05, 1.06},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description:       "SignalNeutral if the rates are flat, the bands collapse onto the mean",
+			rates:             []float64{1.1, 1.1, 1.1, 1.1, 1.1, 1.1, 1.1, 1.1},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description: "ErrInsufficientData if fewer rates than the period",
+			rates:       []float64{1.05, 1.07, 1.05},
+			expErr:      ErrInsufficientData,
+		},
+	}
+
+	e, err := NewBollingerEngine(8, 1.5)
+	assert.NoError(t, err)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			recommendation, err := e.Recommend(ratesListOf(tt.rates...))
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expRecommendation, recommendation.Signal,
+				"recommendation is wrong")
+		})
+	}
+
+	_, err = NewBollingerEngine(1, DefaultBollingerWidth)
+	assert.Error(t, err)
+
+	_, err = NewBollingerEngine(DefaultBollingerPeriod, 0)
+	assert.Error(t, err)
+}
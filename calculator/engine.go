@@ -1,10 +1,12 @@
 package calculator
 
 import (
-	"sort"
+	"fmt"
+	"math"
 
 	"github.com/jeffreyyong/xe/model"
 	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 const (
@@ -13,23 +15,154 @@ const (
 	SignalConvert   Signal = "convert"
 	SignalNoConvert Signal = "don't convert"
 	SignalNeutral   Signal = "neutral"
+
+	// SignalInsufficientData is recommended when engines error
+	// with ErrInsufficientData, i.e. there are too few rates
+	// for the recommendation to be meaningful
+	SignalInsufficientData Signal = "insufficient data"
+
+	// MinDataPoints is the min number of rates
+	// needed to make a recommendation
+	MinDataPoints = 3
+
+	StrengthStrong Strength = "strong"
+	StrengthWeak   Strength = "weak"
+
+	// ThresholdAbsolute compares the absolute slope
+	ThresholdAbsolute ThresholdMode = "absolute"
+
+	// ThresholdRelative compares the absolute slope
+	// relative to the mean rate
+	ThresholdRelative ThresholdMode = "relative"
+
+	// ThresholdPValue compares the p-value of the slope,
+	// the smaller the more significant
+	ThresholdPValue ThresholdMode = "p-value"
+
+	// DefaultTrendThreshold is the default slope relative to
+	// the mean rate, i.e. 0.01% a day of the time axis, within
+	// which a trend is neutral
+	DefaultTrendThreshold = 0.0001
+
+	// DefaultStrongThreshold is the default slope relative to
+	// the mean rate, i.e. 0.1% a day of the time axis, from
+	// which a trend is strong
+	DefaultStrongThreshold = 0.001
 )
 
 // Signal is the custom string for signal
 type Signal string
 
+// Strength is the custom string for how
+// strong the signal is
+type Strength string
+
+// ThresholdMode is the custom string for how the
+// significance of the trend is measured
+type ThresholdMode string
+
 // Engine is the calculator interface that
-// recommends whether should exchange forex
+// recommends whether should exchange forex.
+// It errors with ErrInsufficientData if there are too
+// few rates and with a SeriesError if a rate is invalid.
 type Engine interface {
-	Recommend(ratesList model.RatesList) Signal
+	Recommend(ratesList model.RatesList) (Recommendation, error)
+}
+
+// Recommendation is the signal an engine recommends,
+// with the statistics or votes it was decided by
+// if the engine has any
+type Recommendation struct {
+	Signal   Signal
+	Strength Strength
+	Analysis *Analysis
+	Votes    []Vote
+}
+
+// Analysis holds the statistics of the trend line
+// of the rates
+type Analysis struct {
+	// Slope is the change of the rate per day
+	// of the time axis
+	Slope     float64
+	Intercept float64
+
+	// RSquared is the share of the variance of the
+	// rates explained by the trend line
+	RSquared float64
+
+	// StdErr is the standard error of the slope
+	StdErr float64
+
+	// PValue is the probability of a slope at least as
+	// steep if the rates had no trend
+	PValue float64
+
+	// Mean is the mean of the rates
+	Mean float64
+
+	// Points is the number of rates
+	Points int
+
+	// Gaps is the number of weekdays without a rate,
+	// e.g. holidays, between the first and the last rate
+	Gaps int
+
+	// PercentChange is the change from the first
+	// to the last rate in percent
+	PercentChange float64
 }
 
 type engine struct {
+	mode            ThresholdMode
+	threshold       float64
+	strongThreshold float64
+	axis            TimeAxis
 }
 
 // NewEngine initialises the calculator engine
+// where any trend is significant, measured in
+// calendar days
 func NewEngine() Engine {
-	return &engine{}
+	return &engine{
+		mode:            ThresholdRelative,
+		threshold:       0,
+		strongThreshold: DefaultStrongThreshold,
+		axis:            AxisCalendarDays,
+	}
+}
+
+// NewTrendEngine initialises the calculator engine where trends
+// are significant beyond the threshold and strong beyond the
+// strong threshold. Thresholds are p-values for ThresholdPValue
+// where significant means at or below the threshold.
+// The slope is the change of the rate per day of the time axis.
+func NewTrendEngine(mode ThresholdMode, threshold, strongThreshold float64, axis TimeAxis) (Engine, error) {
+	if _, err := ParseTimeAxis(string(axis)); err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case ThresholdAbsolute, ThresholdRelative:
+		if threshold < 0 || strongThreshold < threshold {
+			return nil, fmt.Errorf("thresholds must satisfy 0 <= threshold <= strong threshold, got %v and %v",
+				threshold, strongThreshold)
+		}
+	case ThresholdPValue:
+		if strongThreshold <= 0 || threshold < strongThreshold || threshold > 1 {
+			return nil, fmt.Errorf("p-values must satisfy 0 < strong threshold <= threshold <= 1, got %v and %v",
+				strongThreshold, threshold)
+		}
+	default:
+		return nil, fmt.Errorf("unknown threshold mode: %s", mode)
+	}
+
+	return &engine{
+		mode:            mode,
+		threshold:       threshold,
+		strongThreshold: strongThreshold,
+		axis:            axis,
+	}, nil
 }
 
 // Recommend:
@@ -37,51 +170,129 @@ func NewEngine() Engine {
 // 2) orders them in ascending order by date (exchangeratesapi
 //    returns the rates in random order)
 // 3) finds the trend line of those rates by calculating
-//    the beta
+//    the beta against the days since the first rate
 // 4) returns 'convert' if the price is cheaper, returns 'don't
 //    convert' if the price is more expensive, and 'neutral'
-//    if the price is constant.
-func (e *engine) Recommend(ratesList model.RatesList) Signal {
-	sortedRates := sortByDate(ratesList)
-	slope := getSlope(sortedRates, EUR)
+//    if the trend is not significant, i.e. within the threshold.
+// 5) grades the signal 'strong' if the trend is beyond the
+//    strong threshold, 'weak' otherwise.
+// The statistics of the trend line are returned as the Analysis.
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates.
+func (e *engine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, MinDataPoints)
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	analysis, err := analyse(series, e.axis)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	recommendation := Recommendation{Signal: SignalNeutral, Analysis: analysis}
+	if analysis.Slope == 0 || !e.beyond(analysis, e.threshold) {
+		return recommendation, nil
+	}
+
+	recommendation.Signal = SignalConvert
+	if analysis.Slope > 0 {
+		recommendation.Signal = SignalNoConvert
+	}
 
-	signal := SignalNeutral
-	if slope > 0 {
-		signal = SignalNoConvert
-	} else if slope < 0 {
-		signal = SignalConvert
+	recommendation.Strength = StrengthWeak
+	if e.beyond(analysis, e.strongThreshold) {
+		recommendation.Strength = StrengthStrong
 	}
+	return recommendation, nil
+}
 
-	return signal
+// beyond tells if the trend is beyond the threshold,
+// i.e. at or below the threshold for p-values and
+// strictly above the threshold otherwise
+func (e *engine) beyond(analysis *Analysis, threshold float64) bool {
+	switch e.mode {
+	case ThresholdAbsolute:
+		return math.Abs(analysis.Slope) > threshold
+	case ThresholdPValue:
+		return analysis.PValue <= threshold
+	default:
+		if analysis.Mean == 0 {
+			return false
+		}
+		return math.Abs(analysis.Slope/analysis.Mean) > threshold
+	}
 }
 
-// sortByDate sorts the rates by the key of RatesList, i.e. date string
-func sortByDate(ratesList model.RatesList) []model.Rates {
-	var dates []string
-	ratesSequence := make([]model.Rates, len(ratesList))
+// analyse computes the beta (trend line) given a series of rates
+// ordered by date, against the days since the first rate on the
+// time axis, with the goodness of fit and the standard error of
+// the beta.
+func analyse(series []model.RatePoint, axis TimeAxis) (*Analysis, error) {
+	length := len(series)
+	days, gaps, err := timeline(series, axis)
+	if err != nil {
+		return nil, err
+	}
+	rates := values(series)
 
-	for d := range ratesList {
-		dates = append(dates, d)
+	alpha, beta := stat.LinearRegression(days, rates, nil, false)
+	analysis := &Analysis{
+		Slope:     beta,
+		Intercept: alpha,
+		Points:    length,
+		Gaps:      gaps,
+		PValue:    1,
+	}
+	if length == 0 {
+		return analysis, nil
 	}
-	sort.Strings(dates)
+	analysis.Mean = stat.Mean(rates, nil)
 
-	for i, d := range dates {
-		ratesSequence[i] = ratesList[d]
+	// R² is undefined for constant rates, which the
+	// trend line fits perfectly
+	analysis.RSquared = 1
+	if stat.Variance(rates, nil) > 0 {
+		analysis.RSquared = stat.RSquared(days, rates, nil, alpha, beta)
+	}
+	analysis.StdErr = slopeStdErr(days, rates, alpha, beta)
+	analysis.PValue = slopePValue(beta, analysis.StdErr, length)
+	if rates[0] != 0 {
+		analysis.PercentChange = (rates[length-1] - rates[0]) / rates[0] * 100
 	}
-	return ratesSequence
+	return analysis, nil
 }
 
-// getSlope computes the beta (trend line) given a list of ordered rates.
-func getSlope(ratesSequence []model.Rates, currency string) float64 {
-	length := len(ratesSequence)
-	timeline := make([]float64, length)
-	rates := make([]float64, length)
+// slopeStdErr computes the standard error of the beta, i.e.
+// sqrt(sum of squared residuals / (n-2) / sum of squared
+// deviations of x). It is 0 if there are fewer than 3 points.
+func slopeStdErr(x, y []float64, alpha, beta float64) float64 {
+	n := len(x)
+	if n < 3 {
+		return 0
+	}
 
-	for i, r := range ratesSequence {
-		timeline[i] = float64(i)
-		rates[i] = r[currency]
+	meanX := stat.Mean(x, nil)
+	var ssr, sxx float64
+	for i := range x {
+		residual := y[i] - (alpha + beta*x[i])
+		ssr += residual * residual
+		sxx += (x[i] - meanX) * (x[i] - meanX)
+	}
+	return math.Sqrt(ssr / float64(n-2) / sxx)
+}
+
+// slopePValue computes the two-sided p-value of the beta with
+// the t-statistic beta / standard error, which follows the
+// Student's t distribution with n-2 degrees of freedom if the
+// rates have no trend. It is 1 if there are fewer than 3 points.
+func slopePValue(beta, stdErr float64, n int) float64 {
+	if n < 3 || beta == 0 {
+		return 1
+	}
+	if stdErr == 0 {
+		return 0
 	}
 
-	_, beta := stat.LinearRegression(timeline, rates, nil, false)
-	return beta
+	t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(n - 2)}
+	return 2 * t.Survival(math.Abs(beta/stdErr))
 }
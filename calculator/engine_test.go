@@ -7,14 +7,14 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// TestSortByDate checks the RatesList is sorted by
-// date in ascending order
+// TestSortedDates checks the dates of RatesList
+// are sorted in ascending order
 // Scenario:
 // 	- given a rates list with dates in random order
 //
 // Expect:
-// 	- rates are sorted in order in a slice
-func TestSortByDate(t *testing.T) {
+// 	- dates are sorted in order in a slice
+func TestSortedDates(t *testing.T) {
 	ratesList := model.RatesList{
 		"2019-11-21": {
 			EUR: 1.1689343994,
@@ -36,65 +36,90 @@ func TestSortByDate(t *testing.T) {
 		},
 	}
 
-	expectedRatesSequence := []model.Rates{
-		{
-			EUR: 1.1674060238,
-		},
-		{
-			EUR: 1.1719207782,
-		},
-		{
-			EUR: 1.1685928973,
-		},
-		{
-			EUR: 1.1666569445,
-		},
-		{
-			EUR: 1.1689343994,
-		},
-		{
-			EUR: 1.163061177,
-		},
+	expectedDates := []string{
+		"2019-11-15",
+		"2019-11-18",
+		"2019-11-19",
+		"2019-11-20",
+		"2019-11-21",
+		"2019-11-22",
 	}
 
-	ratesSequence := sortByDate(ratesList)
-	assert.Equal(t, expectedRatesSequence,
-		ratesSequence, "rates sequence don't match")
+	dates := sortedDates(ratesList)
+	assert.Equal(t, expectedDates,
+		dates, "dates don't match")
+}
+
+// weekSeries is a week of rates from Friday to Friday,
+// without the rates of the weekend
+var weekSeries = []model.RatePoint{
+	{Date: "2019-11-15", Rate: 1.1674060238},
+	{Date: "2019-11-18", Rate: 1.1719207782},
+	{Date: "2019-11-19", Rate: 1.1685928973},
+	{Date: "2019-11-20", Rate: 1.1666569445},
+	{Date: "2019-11-21", Rate: 1.1689343994},
+	{Date: "2019-11-22", Rate: 1.163061177},
 }
 
-// TestGetSlope checks that beta is calculated correctly.
+// TestAnalyse checks that beta and the statistics
+// of the trend line are calculated correctly.
 // Scenario:
-// 	- given a list of rates that has been ordered
+// 	- given a series of rates that has been ordered
+// 	- explained in the descriptions of tests
 //
 // Expect:
 // 	- a beta coefficient is calculated correctly
-func TestGetSlope(t *testing.T) {
-	ratesSequence := []model.Rates{
-		{
-			EUR: 1.1674060238,
-		},
-		{
-			EUR: 1.1719207782,
-		},
-		{
-			EUR: 1.1685928973,
-		},
-		{
-			EUR: 1.1666569445,
-		},
+// 	- R², standard error, p-value, number of points and
+// 	  percent change are calculated correctly
+func TestAnalyse(t *testing.T) {
+	type testParams struct {
+		description  string
+		axis         TimeAxis
+		expSlope     float64
+		expIntercept float64
+		expRSquared  float64
+		expStdErr    float64
+		expPValue    float64
+	}
+
+	cases := []testParams{
 		{
-			EUR: 1.1689343994,
+			description:  "calendar days count the weekend",
+			axis:         AxisCalendarDays,
+			expSlope:     -0.0005058692691891865,
+			expIntercept: 1.1698698253216218,
+			expRSquared:  0.1843286868772852,
+			expStdErr:    0.0005320708072580472,
+			expPValue:    0.39556664122503904,
 		},
 		{
-			EUR: 1.163061177,
+			description:  "business days skip the weekend",
+			axis:         AxisBusinessDays,
+			expSlope:     -0.0009319806628571443,
+			expIntercept: 1.170091988357143,
+			expRSquared:  0.3550975762868147,
+			expStdErr:    0.0006279861366351456,
+			expPValue:    0.211950472564006,
 		},
 	}
 
-	expectedSlope := -0.0009319806628571443
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			analysis, err := analyse(weekSeries, tt.axis)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.expSlope, analysis.Slope, 1e-15, "slope is wrong")
+			assert.InDelta(t, tt.expIntercept, analysis.Intercept, 1e-12, "intercept is wrong")
+			assert.InDelta(t, tt.expRSquared, analysis.RSquared, 1e-9, "R² is wrong")
+			assert.InDelta(t, tt.expStdErr, analysis.StdErr, 1e-12, "standard error is wrong")
+			assert.InDelta(t, tt.expPValue, analysis.PValue, 1e-9, "p-value is wrong")
+			assert.Equal(t, 6, analysis.Points, "points are wrong")
+			assert.Equal(t, 0, analysis.Gaps, "gaps are wrong")
+			assert.InDelta(t, -0.37217957689281067, analysis.PercentChange, 1e-9, "percent change is wrong")
+		})
+	}
 
-	slope := getSlope(ratesSequence, EUR)
-	assert.Equal(t, expectedSlope,
-		slope, "slope is wrong")
+	_, err := analyse([]model.RatePoint{{Date: "22/11/2019", Rate: 1.16}}, AxisCalendarDays)
+	assert.Error(t, err)
 }
 
 // TestRecommend checks that the right signal
@@ -109,6 +134,7 @@ func TestRecommend(t *testing.T) {
 		description       string
 		ratesList         model.RatesList
 		expRecommendation Signal
+		expErr            error
 	}
 
 	cases := []testParams{
@@ -178,14 +204,200 @@ func TestRecommend(t *testing.T) {
 			},
 			expRecommendation: SignalNeutral,
 		},
+		{
+			description: "ErrInsufficientData if too few rates",
+			ratesList: model.RatesList{
+				"2019-11-22": {
+					"EUR": 0.1155735337,
+				},
+				"2019-11-21": {
+					"EUR": 0.1152883939,
+				},
+			},
+			expErr: ErrInsufficientData,
+		},
 	}
 
 	e := NewEngine()
 	for _, tt := range cases {
 		t.Run(tt.description, func(t *testing.T) {
-			recommendation := e.Recommend(tt.ratesList)
-			assert.Equal(t, tt.expRecommendation, recommendation,
+			recommendation, err := e.Recommend(tt.ratesList)
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expRecommendation, recommendation.Signal,
 				"recommendation is wrong")
+			if tt.expErr == nil {
+				assert.NotNil(t, recommendation.Analysis, "analysis is missing")
+			}
+		})
+	}
+}
+
+// TestTrendEngineThresholds checks that small or insignificant
+// trends are neutral and significant ones are graded
+// Scenario:
+// 	- rates going down by -0.04% a calendar day with a p-value of 0.4
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation and strength are given
+func TestTrendEngineThresholds(t *testing.T) {
+	ratesList := model.RatesList{
+		"2019-11-21": {
+			EUR: 1.1689343994,
+		},
+		"2019-11-15": {
+			EUR: 1.1674060238,
+		},
+		"2019-11-22": {
+			EUR: 1.163061177,
+		},
+		"2019-11-20": {
+			EUR: 1.1666569445,
+		},
+		"2019-11-19": {
+			EUR: 1.1685928973,
+		},
+		"2019-11-18": {
+			EUR: 1.1719207782,
+		},
+	}
+
+	type testParams struct {
+		description     string
+		mode            ThresholdMode
+		threshold       float64
+		strongThreshold float64
+		expSignal       Signal
+		expStrength     Strength
+	}
+
+	cases := []testParams{
+		{
+			description:     "absolute slope within the threshold is neutral",
+			mode:            ThresholdAbsolute,
+			threshold:       0.001,
+			strongThreshold: 0.002,
+			expSignal:       SignalNeutral,
+		},
+		{
+			description:     "absolute slope beyond the strong threshold is strong",
+			mode:            ThresholdAbsolute,
+			threshold:       0.0001,
+			strongThreshold: 0.0005,
+			expSignal:       SignalConvert,
+			expStrength:     StrengthStrong,
+		},
+		{
+			description:     "relative slope between the thresholds is weak",
+			mode:            ThresholdRelative,
+			threshold:       0.0002,
+			strongThreshold: 0.001,
+			expSignal:       SignalConvert,
+			expStrength:     StrengthWeak,
+		},
+		{
+			description:     "insignificant p-value is neutral",
+			mode:            ThresholdPValue,
+			threshold:       0.05,
+			strongThreshold: 0.01,
+			expSignal:       SignalNeutral,
+		},
+		{
+			description:     "p-value below the threshold is weak",
+			mode:            ThresholdPValue,
+			threshold:       0.5,
+			strongThreshold: 0.1,
+			expSignal:       SignalConvert,
+			expStrength:     StrengthWeak,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			e, err := NewTrendEngine(tt.mode, tt.threshold, tt.strongThreshold, AxisCalendarDays)
+			assert.NoError(t, err)
+
+			recommendation, err := e.Recommend(ratesList)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expSignal, recommendation.Signal, "recommendation is wrong")
+			assert.Equal(t, tt.expStrength, recommendation.Strength, "strength is wrong")
 		})
 	}
 }
+
+// TestTrendStrategyDefault checks the trend strategy has
+// a neutral band by default
+// Scenario:
+// 	- the trend strategy with the default parameters
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation and strength are given
+func TestTrendStrategyDefault(t *testing.T) {
+	type testParams struct {
+		description string
+		ratesList   model.RatesList
+		expSignal   Signal
+		expStrength Strength
+	}
+
+	cases := []testParams{
+		{
+			description: "tiny nonzero slope is neutral",
+			ratesList: model.RatesList{
+				"2019-11-18": {EUR: 1.1000},
+				"2019-11-19": {EUR: 1.1001},
+				"2019-11-20": {EUR: 1.1000},
+				"2019-11-21": {EUR: 1.1001},
+				"2019-11-22": {EUR: 1.1001},
+			},
+			expSignal: SignalNeutral,
+		},
+		{
+			description: "-0.04% a calendar day is weak",
+			ratesList: model.RatesList{
+				"2019-11-15": {EUR: 1.1674060238},
+				"2019-11-18": {EUR: 1.1719207782},
+				"2019-11-19": {EUR: 1.1685928973},
+				"2019-11-20": {EUR: 1.1666569445},
+				"2019-11-21": {EUR: 1.1689343994},
+				"2019-11-22": {EUR: 1.163061177},
+			},
+			expSignal:   SignalConvert,
+			expStrength: StrengthWeak,
+		},
+	}
+
+	r, err := NewStrategies(DefaultStrategyParams())
+	assert.NoError(t, err)
+	trend, ok := r.Get(StrategyTrend)
+	assert.True(t, ok)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			recommendation, err := trend.Engine.Recommend(tt.ratesList)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expSignal, recommendation.Signal, "recommendation is wrong")
+			assert.Equal(t, tt.expStrength, recommendation.Strength, "strength is wrong")
+		})
+	}
+}
+
+// TestNewTrendEngineInvalid checks invalid
+// modes and thresholds are rejected
+func TestNewTrendEngineInvalid(t *testing.T) {
+	_, err := NewTrendEngine(ThresholdMode("bayesian"), 0, 0, AxisCalendarDays)
+	assert.Error(t, err)
+
+	_, err = NewTrendEngine(ThresholdAbsolute, 0.002, 0.001, AxisCalendarDays)
+	assert.Error(t, err)
+
+	_, err = NewTrendEngine(ThresholdPValue, 0.01, 0.05, AxisCalendarDays)
+	assert.Error(t, err)
+
+	_, err = NewTrendEngine(ThresholdPValue, 0.05, 0, AxisCalendarDays)
+	assert.Error(t, err)
+
+	_, err = NewTrendEngine(ThresholdAbsolute, 0, 0, TimeAxis("hours"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	StrategyEnsemble = "ensemble"
+
+	// VoteMajority gives every member one vote
+	VoteMajority VoteMode = "majority"
+
+	// VoteWeighted gives every member its weight in votes
+	VoteWeighted VoteMode = "weighted"
+)
+
+// VoteMode is the custom string for how
+// the votes of an ensemble are counted
+type VoteMode string
+
+// Member is an engine voting in an ensemble
+type Member struct {
+	Name   string
+	Engine Engine
+	Weight float64
+}
+
+// Vote is the signal a member of an ensemble voted for
+type Vote struct {
+	Strategy string
+	Signal   Signal
+	Weight   float64
+}
+
+type ensembleEngine struct {
+	mode    VoteMode
+	members []Member
+}
+
+// NewEnsembleEngine initialises the calculator engine that
+// recommends the signal the majority of its members vote for.
+// For VoteWeighted the weights must not be negative and must
+// not sum to 0, as no member would have a vote then.
+func NewEnsembleEngine(mode VoteMode, members ...Member) (Engine, error) {
+	if mode != VoteMajority && mode != VoteWeighted {
+		return nil, fmt.Errorf("unknown vote mode: %s", mode)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("ensemble must have members")
+	}
+	total := 0.0
+	for _, m := range members {
+		if m.Engine == nil {
+			return nil, fmt.Errorf("member %s must have an engine", m.Name)
+		}
+		if mode == VoteWeighted && m.Weight < 0 {
+			return nil, fmt.Errorf("member %s must not have a negative weight", m.Name)
+		}
+		total += m.Weight
+	}
+	if mode == VoteWeighted && total == 0 {
+		return nil, fmt.Errorf("weights of the members must not sum to 0")
+	}
+
+	return &ensembleEngine{
+		mode:    mode,
+		members: members,
+	}, nil
+}
+
+// Recommend:
+// 1) asks every member for its recommendation
+// 2) counts the votes, one per member for VoteMajority and
+//    the weight of the member for VoteWeighted. Members with
+//    insufficient data abstain, voting 'insufficient data'.
+// 3) returns the signal with more than half of the votes,
+//    and 'neutral' if no signal has a majority.
+// The votes of the members are returned with the signal.
+// It returns ErrInsufficientData if every member with a vote
+// abstains, and the error of the first member that fails
+// otherwise.
+func (e *ensembleEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	recommendation := Recommendation{Votes: make([]Vote, len(e.members))}
+	tally := map[Signal]float64{}
+	total := 0.0
+
+	for i, m := range e.members {
+		weight := 1.0
+		if e.mode == VoteWeighted {
+			weight = m.Weight
+		}
+
+		vote, err := m.Engine.Recommend(ratesList)
+		if err == ErrInsufficientData {
+			recommendation.Votes[i] = Vote{Strategy: m.Name, Signal: SignalInsufficientData, Weight: weight}
+			continue
+		}
+		if err != nil {
+			return Recommendation{}, err
+		}
+
+		recommendation.Votes[i] = Vote{Strategy: m.Name, Signal: vote.Signal, Weight: weight}
+		tally[vote.Signal] += weight
+		total += weight
+	}
+
+	if total == 0 {
+		return Recommendation{}, ErrInsufficientData
+	}
+
+	recommendation.Signal = SignalNeutral
+	for signal, votes := range tally {
+		if votes > total/2 {
+			recommendation.Signal = signal
+		}
+	}
+	return recommendation, nil
+}
@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedEngine always recommends the same signal,
+// SignalInsufficientData is returned as ErrInsufficientData
+type fixedEngine Signal
+
+func (e fixedEngine) Recommend(model.RatesList) (Recommendation, error) {
+	if Signal(e) == SignalInsufficientData {
+		return Recommendation{}, ErrInsufficientData
+	}
+	return Recommendation{Signal: Signal(e)}, nil
+}
+
+// failingEngine always fails with the same error
+type failingEngine struct {
+	err error
+}
+
+func (e failingEngine) Recommend(model.RatesList) (Recommendation, error) {
+	return Recommendation{}, e.err
+}
+
+// TestEnsembleRecommend checks the votes of the members are
+// counted and the signal with the majority is recommended
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation and votes are given
+func TestEnsembleRecommend(t *testing.T) {
+	type testParams struct {
+		description string
+		mode        VoteMode
+		members     []Member
+		expSignal   Signal
+		expErr      error
+	}
+
+	cases := []testParams{
+		{
+			description: "majority wins",
+			mode:        VoteMajority,
+			members: []Member{
+				{Name: "a", Engine: fixedEngine(SignalConvert), Weight: 1},
+				{Name: "b", Engine: fixedEngine(SignalConvert), Weight: 1},
+				{Name: "c", Engine: fixedEngine(SignalNoConvert), Weight: 5},
+			},
+			expSignal: SignalConvert,
+		},
+		{
+			description: "weights decide in weighted mode",
+			mode:        VoteWeighted,
+			members: []Member{
+				{Name: "a", Engine: fixedEngine(SignalConvert), Weight: 1},
+				{Name: "b", Engine: fixedEngine(SignalConvert), Weight: 1},
+				{Name: "c", Engine: fixedEngine(SignalNoConvert), Weight: 5},
+			},
+			expSignal: SignalNoConvert,
+		},
+		{
+			description: "SignalNeutral without a majority",
+			mode:        VoteMajority,
+			members: []Member{
+				{Name: "a", Engine: fixedEngine(SignalConvert)},
+				{Name: "b", Engine: fixedEngine(SignalNoConvert)},
+				{Name: "c", Engine: fixedEngine(SignalNeutral)},
+			},
+			expSignal: SignalNeutral,
+		},
+		{
+			description: "members with insufficient data abstain",
+			mode:        VoteMajority,
+			members: []Member{
+				{Name: "a", Engine: fixedEngine(SignalNoConvert)},
+				{Name: "b", Engine: fixedEngine(SignalInsufficientData)},
+				{Name: "c", Engine: fixedEngine(SignalInsufficientData)},
+			},
+			expSignal: SignalNoConvert,
+		},
+		{
+			description: "ErrInsufficientData if every member abstains",
+			mode:        VoteMajority,
+			members: []Member{
+				{Name: "a", Engine: fixedEngine(SignalInsufficientData)},
+			},
+			expErr: ErrInsufficientData,
+		},
+		{
+			description: "error of a member is returned",
+			mode:        VoteMajority,
+			members: []Member{
+				{Name: "a", Engine: fixedEngine(SignalConvert)},
+				{Name: "b", Engine: failingEngine{NewSeriesError("2019-11-22", "rate is not positive")}},
+			},
+			expErr: NewSeriesError("2019-11-22", "rate is not positive"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			e, err := NewEnsembleEngine(tt.mode, tt.members...)
+			assert.NoError(t, err)
+
+			recommendation, err := e.Recommend(model.RatesList{})
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			if tt.expErr != nil {
+				return
+			}
+
+			assert.Equal(t, tt.expSignal, recommendation.Signal, "recommendation is wrong")
+			assert.Len(t, recommendation.Votes, len(tt.members))
+			for i, m := range tt.members {
+				assert.Equal(t, m.Name, recommendation.Votes[i].Strategy)
+				assert.Equal(t, Signal(m.Engine.(fixedEngine)), recommendation.Votes[i].Signal)
+			}
+		})
+	}
+}
+
+// TestNewEnsembleEngineInvalid checks invalid
+// vote modes and members are rejected
+func TestNewEnsembleEngineInvalid(t *testing.T) {
+	_, err := NewEnsembleEngine(VoteMode("unanimous"), Member{Name: "a", Engine: fixedEngine(SignalConvert)})
+	assert.Error(t, err)
+
+	_, err = NewEnsembleEngine(VoteMajority)
+	assert.Error(t, err)
+
+	_, err = NewEnsembleEngine(VoteWeighted, Member{Name: "a", Engine: fixedEngine(SignalConvert), Weight: -1})
+	assert.Error(t, err)
+
+	_, err = NewEnsembleEngine(VoteWeighted,
+		Member{Name: "a", Engine: fixedEngine(SignalConvert)},
+		Member{Name: "b", Engine: fixedEngine(SignalNoConvert)})
+	assert.Error(t, err)
+
+	// the weights are ignored by a majority vote
+	_, err = NewEnsembleEngine(VoteMajority, Member{Name: "a", Engine: fixedEngine(SignalConvert)})
+	assert.NoError(t, err)
+}
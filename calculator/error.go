@@ -0,0 +1,30 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInsufficientData is returned by engines when there
+// are too few rates for the recommendation to be meaningful
+var ErrInsufficientData = errors.New("insufficient data")
+
+// SeriesError is an error type that contains
+// the date and the reason the rate of that date
+// can't be used for a recommendation.
+// It implements the golang error interface
+// by having the Error() method.
+type SeriesError struct {
+	date string
+	msg  string
+}
+
+func (e *SeriesError) Error() string {
+	return fmt.Sprintf("invalid rate on %s: %s", e.date, e.msg)
+}
+
+// NewSeriesError initialises a SeriesError
+// given the date and msg.
+func NewSeriesError(date, msg string) error {
+	return &SeriesError{date, msg}
+}
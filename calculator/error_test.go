@@ -0,0 +1,17 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewSeriesError checks that the right format
+// of error is constructed
+func TestNewSeriesError(t *testing.T) {
+	err := NewSeriesError("2019-11-22", "missing EUR rate")
+	assert.EqualError(t, err, "invalid rate on 2019-11-22: missing EUR rate")
+
+	_, ok := err.(*SeriesError)
+	assert.True(t, ok, "error is not a SeriesError")
+}
@@ -0,0 +1,191 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const (
+	ForecastHolt ForecastMethod = "holt"
+	ForecastAR   ForecastMethod = "ar"
+
+	// DefaultForecastDays is the default number
+	// of business days to forecast
+	DefaultForecastDays = 5
+
+	// DefaultConfidence is the default probability of
+	// the rate being within the prediction interval
+	DefaultConfidence = 0.95
+)
+
+// ForecastMethod is how the rates are forecast
+type ForecastMethod string
+
+// Prediction is the forecast rate of a date, the rate is
+// within the lower and upper bounds with the confidence
+// of the forecaster
+type Prediction struct {
+	Date  string
+	Rate  float64
+	Lower float64
+	Upper float64
+}
+
+// Forecaster predicts the rates of the business
+// days following the series
+type Forecaster interface {
+	// Forecast returns the predictions of the next days
+	// business days after the last rate of the series.
+	// It returns ErrInsufficientData if there are too
+	// few rates to fit the model.
+	Forecast(series []model.RatePoint, days int) ([]Prediction, error)
+
+	// MinRates returns the number of rates
+	// the forecaster needs to fit the model
+	MinRates() int
+}
+
+// Forecasters is a set of forecasters by method
+type Forecasters map[ForecastMethod]Forecaster
+
+// NewForecasters initialises the built-in
+// forecasters tuned with the given parameters
+func NewForecasters(p StrategyParams) (Forecasters, error) {
+	holt, err := NewHoltForecaster(p.HoltAlpha, p.HoltBeta, p.ForecastConfidence)
+	if err != nil {
+		return nil, err
+	}
+	ar, err := NewARForecaster(p.AROrder, p.ForecastConfidence)
+	if err != nil {
+		return nil, err
+	}
+
+	return Forecasters{
+		ForecastHolt: holt,
+		ForecastAR:   ar,
+	}, nil
+}
+
+// ParseForecastMethod parses the forecast method,
+// it defaults to holt if s is empty
+func ParseForecastMethod(s string) (ForecastMethod, error) {
+	switch m := ForecastMethod(s); m {
+	case "":
+		return ForecastHolt, nil
+	case ForecastHolt, ForecastAR:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown forecast method: %s", s)
+	}
+}
+
+type forecastEngine struct {
+	forecaster Forecaster
+	days       int
+}
+
+// NewForecastEngine initialises the calculator engine that
+// recommends on the rate forecast in days business days
+func NewForecastEngine(f Forecaster, days int) (Engine, error) {
+	if f == nil {
+		return nil, fmt.Errorf("forecaster must be provided")
+	}
+	if days < 1 {
+		return nil, fmt.Errorf("days must be positive, got %d", days)
+	}
+
+	return &forecastEngine{
+		forecaster: f,
+		days:       days,
+	}, nil
+}
+
+// Recommend:
+// 1) takes a list of rates and orders them by date
+// 2) forecasts the rate in days business days
+// 3) returns 'don't convert' if the rate is forecast to rise,
+//    'convert' if it is forecast to fall and 'neutral' if it
+//    is forecast to stay the same.
+// 4) grades the signal 'strong' if the whole prediction interval
+//    is above or below the latest rate, 'weak' otherwise.
+// It returns ErrInsufficientData if there are too few rates
+// for the forecaster.
+func (e *forecastEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, MinDataPoints)
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	predictions, err := e.forecaster.Forecast(series, e.days)
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	latest := series[len(series)-1].Rate
+	p := predictions[len(predictions)-1]
+	switch {
+	case p.Lower > latest:
+		return Recommendation{Signal: SignalNoConvert, Strength: StrengthStrong}, nil
+	case p.Upper < latest:
+		return Recommendation{Signal: SignalConvert, Strength: StrengthStrong}, nil
+	case p.Rate > latest:
+		return Recommendation{Signal: SignalNoConvert, Strength: StrengthWeak}, nil
+	case p.Rate < latest:
+		return Recommendation{Signal: SignalConvert, Strength: StrengthWeak}, nil
+	}
+	return Recommendation{Signal: SignalNeutral}, nil
+}
+
+// predictionIntervals builds the predictions of the next
+// business days after last, from the forecast rates and
+// the variances of their forecast errors
+func predictionIntervals(last string, rates, variances []float64, confidence float64) ([]Prediction, error) {
+	dates, err := nextBusinessDays(last, len(rates))
+	if err != nil {
+		return nil, err
+	}
+
+	z := distuv.UnitNormal.Quantile(0.5 + confidence/2)
+	predictions := make([]Prediction, len(rates))
+	for i, r := range rates {
+		margin := z * math.Sqrt(variances[i])
+		predictions[i] = Prediction{
+			Date:  dates[i],
+			Rate:  r,
+			Lower: r - margin,
+			Upper: r + margin,
+		}
+	}
+	return predictions, nil
+}
+
+// nextBusinessDays returns the n weekdays after the date
+func nextBusinessDays(after string, n int) ([]string, error) {
+	d, err := date.Parse(after)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, n)
+	for len(dates) < n {
+		d = d.AddDate(0, 0, 1)
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			dates = append(dates, date.Format(d))
+		}
+	}
+	return dates, nil
+}
+
+// validConfidence checks the confidence
+// is a probability between 0 and 1
+func validConfidence(confidence float64) error {
+	if !(confidence > 0 && confidence < 1) {
+		return fmt.Errorf("confidence must be between 0 and 1, got %v", confidence)
+	}
+	return nil
+}
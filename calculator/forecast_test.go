@@ -0,0 +1,121 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedForecaster always forecasts the same
+// rate with the same prediction interval
+type fixedForecaster Prediction
+
+func (f fixedForecaster) Forecast(series []model.RatePoint, days int) ([]Prediction, error) {
+	return []Prediction{Prediction(f)}, nil
+}
+
+func (f fixedForecaster) MinRates() int {
+	return MinDataPoints
+}
+
+// TestForecastRecommend checks that the right signal
+// recommendation is given based on the forecast
+// Scenario:
+// 	- latest rate is 1.10
+// 	- other cases explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation and strength are given
+func TestForecastRecommend(t *testing.T) {
+	type testParams struct {
+		description string
+		forecaster  Forecaster
+		expSignal   Signal
+		expStrength Strength
+		expErr      error
+	}
+
+	cases := []testParams{
+		{
+			description: "strong SignalNoConvert if the interval is above the latest rate",
+			forecaster:  fixedForecaster{Rate: 1.12, Lower: 1.11, Upper: 1.13},
+			expSignal:   SignalNoConvert,
+			expStrength: StrengthStrong,
+		},
+		{
+			description: "strong SignalConvert if the interval is below the latest rate",
+			forecaster:  fixedForecaster{Rate: 1.08, Lower: 1.07, Upper: 1.09},
+			expSignal:   SignalConvert,
+			expStrength: StrengthStrong,
+		},
+		{
+			description: "weak SignalNoConvert if the forecast rate is above the latest rate",
+			forecaster:  fixedForecaster{Rate: 1.11, Lower: 1.08, Upper: 1.14},
+			expSignal:   SignalNoConvert,
+			expStrength: StrengthWeak,
+		},
+		{
+			description: "weak SignalConvert if the forecast rate is below the latest rate",
+			forecaster:  fixedForecaster{Rate: 1.09, Lower: 1.06, Upper: 1.12},
+			expSignal:   SignalConvert,
+			expStrength: StrengthWeak,
+		},
+		{
+			description: "SignalNeutral if the forecast rate is the latest rate",
+			forecaster:  fixedForecaster{Rate: 1.10, Lower: 1.10, Upper: 1.10},
+			expSignal:   SignalNeutral,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			e, err := NewForecastEngine(tt.forecaster, 1)
+			assert.NoError(t, err)
+
+			recommendation, err := e.Recommend(ratesListOf(1.08, 1.09, 1.10))
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expSignal, recommendation.Signal, "recommendation is wrong")
+			assert.Equal(t, tt.expStrength, recommendation.Strength, "strength is wrong")
+		})
+	}
+}
+
+// TestForecastRecommendInsufficientData checks the
+// forecaster needs enough rates to fit the model
+func TestForecastRecommendInsufficientData(t *testing.T) {
+	ar, err := NewARForecaster(DefaultAROrder, DefaultConfidence)
+	assert.NoError(t, err)
+	e, err := NewForecastEngine(ar, DefaultForecastDays)
+	assert.NoError(t, err)
+
+	_, err = e.Recommend(ratesListOf(1.08, 1.09, 1.10))
+	assert.Equal(t, ErrInsufficientData, err)
+
+	_, err = NewForecastEngine(ar, 0)
+	assert.Error(t, err)
+}
+
+// TestNextBusinessDays checks weekends are skipped
+func TestNextBusinessDays(t *testing.T) {
+	dates, err := nextBusinessDays("2019-11-21", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2019-11-22", "2019-11-25", "2019-11-26"}, dates)
+
+	_, err = nextBusinessDays("21/11/2019", 3)
+	assert.Error(t, err)
+}
+
+// TestParseForecastMethod checks that only known methods are accepted
+func TestParseForecastMethod(t *testing.T) {
+	method, err := ParseForecastMethod("")
+	assert.NoError(t, err)
+	assert.Equal(t, ForecastHolt, method)
+
+	method, err = ParseForecastMethod("ar")
+	assert.NoError(t, err)
+	assert.Equal(t, ForecastAR, method)
+
+	_, err = ParseForecastMethod("arima")
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// DefaultHoltAlpha is the default smoothing
+	// parameter of the level
+	DefaultHoltAlpha = 0.5
+
+	// DefaultHoltBeta is the default smoothing
+	// parameter of the trend
+	DefaultHoltBeta = 0.1
+)
+
+type holtForecaster struct {
+	alpha      float64
+	beta       float64
+	confidence float64
+}
+
+// NewHoltForecaster initialises the forecaster using
+// Holt's linear exponential smoothing, alpha smooths the
+// level and beta smooths the trend of the rates
+func NewHoltForecaster(alpha, beta, confidence float64) (Forecaster, error) {
+	if alpha <= 0 || alpha > 1 {
+		return nil, fmt.Errorf("alpha must be in (0, 1], got %v", alpha)
+	}
+	if beta < 0 || beta > 1 {
+		return nil, fmt.Errorf("beta must be in [0, 1], got %v", beta)
+	}
+	if err := validConfidence(confidence); err != nil {
+		return nil, err
+	}
+
+	return &holtForecaster{
+		alpha:      alpha,
+		beta:       beta,
+		confidence: confidence,
+	}, nil
+}
+
+// MinRates returns MinDataPoints
+func (f *holtForecaster) MinRates() int {
+	return MinDataPoints
+}
+
+// Forecast:
+// 1) starts with the first rate as the level and the change
+//    between the first two rates as the trend
+// 2) updates the level and the trend with every rate
+// 3) forecasts the rate h days ahead as level + h * trend
+// 4) estimates the variance of the h days ahead forecast error
+//    from the one day ahead errors of the fit,
+//    σ² * (1 + (h-1) * (α² + αβh + β²h(2h-1)/6))
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates.
+func (f *holtForecaster) Forecast(series []model.RatePoint, days int) ([]Prediction, error) {
+	if len(series) < MinDataPoints {
+		return nil, ErrInsufficientData
+	}
+	rates := values(series)
+
+	level, trend := rates[0], rates[1]-rates[0]
+	sse := 0.0
+	for _, r := range rates[1:] {
+		e := r - (level + trend)
+		sse += e * e
+
+		prev := level
+		level = f.alpha*r + (1-f.alpha)*(level+trend)
+		trend = f.beta*(level-prev) + (1-f.beta)*trend
+	}
+	// the first error is 0 as the trend starts with it
+	variance := sse / float64(len(rates)-2)
+
+	a, b := f.alpha, f.beta
+	forecast := make([]float64, days)
+	variances := make([]float64, days)
+	for i := range forecast {
+		h := float64(i + 1)
+		forecast[i] = level + h*trend
+		variances[i] = variance * (1 + (h-1)*(a*a+a*b*h+b*b*h*(2*h-1)/6))
+	}
+	return predictionIntervals(series[len(series)-1].Date, forecast, variances, f.confidence)
+}
@@ -0,0 +1,54 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHoltForecast checks the forecast and prediction
+// intervals of Holt's linear exponential smoothing
+// Scenario:
+// 	- rising rates from Friday 2019-11-01 to Thursday 2019-11-07
+// 	- forecast of 3 business days
+//
+// Expect:
+// 	- rates continue to rise with widening intervals
+// 	- weekend is skipped
+func TestHoltForecast(t *testing.T) {
+	f, err := NewHoltForecaster(DefaultHoltAlpha, DefaultHoltBeta, DefaultConfidence)
+	assert.NoError(t, err)
+
+	series := Series(ratesListOf(1.10, 1.12, 1.11, 1.13, 1.15, 1.14, 1.16), EUR)
+	predictions, err := f.Forecast(series, 3)
+	assert.NoError(t, err)
+
+	expPredictions := []Prediction{
+		{Date: "2019-11-08", Rate: 1.1810718343749997, Lower: 1.1407309598104147, Upper: 1.2214127089395848},
+		{Date: "2019-11-11", Rate: 1.1966216999999997, Lower: 1.149576560203583, Upper: 1.2436668397964163},
+		{Date: "2019-11-12", Rate: 1.2121715656249998, Lower: 1.15730204405632, Upper: 1.2670410871936797},
+	}
+	assert.Len(t, predictions, len(expPredictions))
+	for i, p := range expPredictions {
+		assert.Equal(t, p.Date, predictions[i].Date, "date is wrong")
+		assert.InDelta(t, p.Rate, predictions[i].Rate, 1e-9, "rate is wrong")
+		assert.InDelta(t, p.Lower, predictions[i].Lower, 1e-9, "lower bound is wrong")
+		assert.InDelta(t, p.Upper, predictions[i].Upper, 1e-9, "upper bound is wrong")
+	}
+
+	_, err = f.Forecast(Series(ratesListOf(1.10, 1.12), EUR), 3)
+	assert.Equal(t, ErrInsufficientData, err)
+}
+
+// TestNewHoltForecasterInvalid checks invalid
+// smoothing parameters and confidence are rejected
+func TestNewHoltForecasterInvalid(t *testing.T) {
+	_, err := NewHoltForecaster(0, DefaultHoltBeta, DefaultConfidence)
+	assert.Error(t, err)
+
+	_, err = NewHoltForecaster(DefaultHoltAlpha, 1.5, DefaultConfidence)
+	assert.Error(t, err)
+
+	_, err = NewHoltForecaster(DefaultHoltAlpha, DefaultHoltBeta, 1)
+	assert.Error(t, err)
+}
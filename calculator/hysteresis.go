@@ -0,0 +1,63 @@
+package calculator
+
+// SignalStore persists the last signal emitted
+// per key, e.g. per currency pair and strategy
+type SignalStore interface {
+	// Last returns the last signal saved under the
+	// key, false if none was saved
+	Last(key string) (Signal, bool, error)
+
+	// Update calls fn with the signal carried into the day
+	// under the key and saves the signal fn returns as the
+	// signal of the day, atomically so concurrent updates of
+	// the key are not lost
+	Update(key, day string, fn func(previous Signal) Signal) error
+}
+
+// DailySignal is the signal saved under a key on a day,
+// with the signal carried into the day, i.e. the last
+// signal saved on an earlier day
+type DailySignal struct {
+	Day      string `json:"day"`
+	Previous Signal `json:"previous,omitempty"`
+	Signal   Signal `json:"signal"`
+}
+
+// Advance returns the signal of the day given fn, which
+// returns the signal from the signal carried into the day.
+// The signal carried into the day only advances on a later
+// day, so requests on the same day are all damped by the
+// signal of the day before. Days are YYYY-MM-DD.
+func (s DailySignal) Advance(day string, fn func(previous Signal) Signal) DailySignal {
+	if day > s.Day {
+		s.Day, s.Previous = day, s.Signal
+	}
+	s.Signal = fn(s.Previous)
+	return s
+}
+
+// Hysteresis damps the recommendation so it does not flip between
+// 'convert' and 'don't convert' on consecutive days:
+// 1) a recommendation opposite to the previous signal flips only
+//    if it is strong, i.e. its metric crossed the strong threshold,
+//    which is a wider band than the threshold of a weak signal
+// 2) otherwise it is damped to 'neutral', so the signal has to
+//    pass through 'neutral' before it flips.
+// Only the trend, kalman and forecast engines grade the strength,
+// there is no exit band for the other engines, so their signal
+// always passes through 'neutral' however far the metric went.
+// It returns true if the recommendation was damped.
+func Hysteresis(previous Signal, r Recommendation) (Recommendation, bool) {
+	if !opposite(previous, r.Signal) || r.Strength == StrengthStrong {
+		return r, false
+	}
+
+	r.Signal, r.Strength = SignalNeutral, ""
+	return r, true
+}
+
+// opposite tells if one signal is 'convert'
+// and the other 'don't convert'
+func opposite(a, b Signal) bool {
+	return (a == SignalConvert && b == SignalNoConvert) || (a == SignalNoConvert && b == SignalConvert)
+}
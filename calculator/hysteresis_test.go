@@ -0,0 +1,143 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHysteresis checks that only strong signals flip
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given
+func TestHysteresis(t *testing.T) {
+	type testParams struct {
+		description string
+		previous    Signal
+		r           Recommendation
+		expSignal   Signal
+		expStrength Strength
+		expDamped   bool
+	}
+
+	cases := []testParams{
+		{
+			description: "weak opposite signal is damped to neutral",
+			previous:    SignalConvert,
+			r:           Recommendation{Signal: SignalNoConvert, Strength: StrengthWeak},
+			expSignal:   SignalNeutral,
+			expDamped:   true,
+		},
+		{
+			description: "ungraded opposite signal is damped to neutral",
+			previous:    SignalNoConvert,
+			r:           Recommendation{Signal: SignalConvert},
+			expSignal:   SignalNeutral,
+			expDamped:   true,
+		},
+		{
+			description: "strong opposite signal flips",
+			previous:    SignalNoConvert,
+			r:           Recommendation{Signal: SignalConvert, Strength: StrengthStrong},
+			expSignal:   SignalConvert,
+			expStrength: StrengthStrong,
+		},
+		{
+			description: "weak signal after neutral is given",
+			previous:    SignalNeutral,
+			r:           Recommendation{Signal: SignalNoConvert, Strength: StrengthWeak},
+			expSignal:   SignalNoConvert,
+			expStrength: StrengthWeak,
+		},
+		{
+			description: "same signal is given",
+			previous:    SignalConvert,
+			r:           Recommendation{Signal: SignalConvert, Strength: StrengthWeak},
+			expSignal:   SignalConvert,
+			expStrength: StrengthWeak,
+		},
+		{
+			description: "signal without previous signal is given",
+			r:           Recommendation{Signal: SignalConvert},
+			expSignal:   SignalConvert,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			r, damped := Hysteresis(tt.previous, tt.r)
+			assert.Equal(t, tt.expSignal, r.Signal, "recommendation is wrong")
+			assert.Equal(t, tt.expStrength, r.Strength, "strength is wrong")
+			assert.Equal(t, tt.expDamped, damped)
+		})
+	}
+}
+
+// TestHysteresisUngradedEngine checks the signal of an engine
+// which doesn't grade the strength passes through 'neutral'
+// Scenario:
+// 	- the zscore engine recommends 'convert' on a latest rate
+// 	  far above the mean, after a 'don't convert' signal and
+// 	  after a 'neutral' signal
+//
+// Expect:
+// 	- the recommendation is ungraded
+// 	- it is damped to 'neutral' after 'don't convert', however
+// 	  far the latest rate is from the mean
+// 	- it is given after 'neutral'
+func TestHysteresisUngradedEngine(t *testing.T) {
+	e, err := NewZScoreEngine(DefaultZScoreThreshold)
+	assert.NoError(t, err)
+
+	r, err := e.Recommend(model.RatesList{
+		"2019-11-18": model.Rates{"EUR": 1.10},
+		"2019-11-19": model.Rates{"EUR": 1.10},
+		"2019-11-20": model.Rates{"EUR": 1.11},
+		"2019-11-21": model.Rates{"EUR": 1.10},
+		"2019-11-22": model.Rates{"EUR": 1.50},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Recommendation{Signal: SignalConvert}, r)
+
+	damped, ok := Hysteresis(SignalNoConvert, r)
+	assert.True(t, ok)
+	assert.Equal(t, SignalNeutral, damped.Signal)
+
+	given, ok := Hysteresis(SignalNeutral, r)
+	assert.False(t, ok)
+	assert.Equal(t, SignalConvert, given.Signal)
+}
+
+// TestDailySignalAdvance checks the signal carried
+// into the day advances only on a later day
+// Scenario:
+// 	- signals are saved on a day, again on the same day,
+// 	  on a later day and on an earlier day
+//
+// Expect:
+// 	- the signal of the day before is carried into the day
+// 	- the signal saved on an earlier day is damped by the
+// 	  signal carried into the latest day
+func TestDailySignalAdvance(t *testing.T) {
+	var previous []Signal
+	signalOf := func(signal Signal) func(Signal) Signal {
+		return func(p Signal) Signal {
+			previous = append(previous, p)
+			return signal
+		}
+	}
+
+	s := DailySignal{}.Advance("2019-11-21", signalOf(SignalConvert))
+	s = s.Advance("2019-11-22", signalOf(SignalNeutral))
+	s = s.Advance("2019-11-22", signalOf(SignalNoConvert))
+	assert.Equal(t, DailySignal{Day: "2019-11-22", Previous: SignalConvert, Signal: SignalNoConvert}, s)
+
+	s = s.Advance("2019-11-25", signalOf(SignalNoConvert))
+	s = s.Advance("2019-11-20", signalOf(SignalNeutral))
+	assert.Equal(t, DailySignal{Day: "2019-11-25", Previous: SignalNoConvert, Signal: SignalNeutral}, s)
+
+	assert.Equal(t, []Signal{"", SignalConvert, SignalConvert, SignalNoConvert, SignalNoConvert}, previous)
+}
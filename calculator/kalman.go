@@ -0,0 +1,230 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jeffreyyong/xe/model"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	// DefaultKalmanLevelNoise is the default variance of the
+	// daily change of the level relative to the noise of the rates
+	DefaultKalmanLevelNoise = 0.1
+
+	// DefaultKalmanSlopeNoise is the default variance of the
+	// daily change of the slope relative to the noise of the rates
+	DefaultKalmanSlopeNoise = 0.01
+
+	// DefaultKalmanThreshold is the default number of standard
+	// errors the filtered slope must be away from 0 for a signal
+	DefaultKalmanThreshold = 2.0
+
+	// DefaultKalmanStrongThreshold is the default number of standard
+	// errors the filtered slope must be away from 0 for a strong signal
+	DefaultKalmanStrongThreshold = 3.0
+
+	// outlierThreshold is the number of standard deviations
+	// of the predicted rate beyond which a rate is an outlier,
+	// the innovation of an outlier is clipped to the threshold
+	outlierThreshold = 3.0
+
+	// minNoise is the min variance of the noise of the
+	// rates, so rates without noise can be filtered
+	minNoise = 1e-12
+)
+
+// KalmanEstimate is the level and slope of the
+// rates filtered by the Kalman filter
+type KalmanEstimate struct {
+	Level       float64
+	Slope       float64
+	SlopeStdErr float64
+	Points      int
+}
+
+type kalmanEngine struct {
+	levelNoise      float64
+	slopeNoise      float64
+	threshold       float64
+	strongThreshold float64
+	axis            TimeAxis
+}
+
+// NewKalmanEngine initialises the calculator engine that
+// recommends on the slope of the rates estimated by a Kalman
+// filter with a local linear trend model. The level and slope
+// noise are relative to the noise of the rates, the thresholds
+// are numbers of standard errors of the slope.
+func NewKalmanEngine(levelNoise, slopeNoise, threshold, strongThreshold float64, axis TimeAxis) (Engine, error) {
+	if levelNoise < 0 || slopeNoise < 0 {
+		return nil, fmt.Errorf("level and slope noise must not be negative, got %v and %v", levelNoise, slopeNoise)
+	}
+	if threshold < 0 || strongThreshold < threshold {
+		return nil, fmt.Errorf("thresholds must not be negative and strong threshold must be at least %v, got %v",
+			threshold, strongThreshold)
+	}
+	if _, err := ParseTimeAxis(string(axis)); err != nil {
+		return nil, err
+	}
+
+	return &kalmanEngine{
+		levelNoise:      levelNoise,
+		slopeNoise:      slopeNoise,
+		threshold:       threshold,
+		strongThreshold: strongThreshold,
+		axis:            axis,
+	}, nil
+}
+
+// Recommend:
+// 1) takes a list of rates and orders them by date
+// 2) filters the level and the slope of the rates
+// 3) returns 'neutral' if the slope is within threshold standard
+//    errors of 0, otherwise 'don't convert' if the slope is
+//    positive, i.e. 1 currency will buy more euros later, and
+//    'convert' if it is negative.
+// 4) grades the signal 'strong' if the slope is beyond strong
+//    threshold standard errors, 'weak' otherwise.
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates.
+func (e *kalmanEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, MinDataPoints)
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	estimate, err := e.filter(series)
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	z := math.Inf(1)
+	if estimate.SlopeStdErr > 0 {
+		z = math.Abs(estimate.Slope) / estimate.SlopeStdErr
+	}
+	if estimate.Slope == 0 || z <= e.threshold {
+		return Recommendation{Signal: SignalNeutral}, nil
+	}
+
+	recommendation := Recommendation{Signal: SignalConvert, Strength: StrengthWeak}
+	if estimate.Slope > 0 {
+		recommendation.Signal = SignalNoConvert
+	}
+	if z > e.strongThreshold {
+		recommendation.Strength = StrengthStrong
+	}
+	return recommendation, nil
+}
+
+// filter runs the Kalman filter of the local linear trend model
+//   rate(t)  = level(t) + noise
+//   level(t) = level(t-dt) + dt * slope(t-dt) + level noise
+//   slope(t) = slope(t-dt) + slope noise
+// over the series, where dt is the days between consecutive
+// rates on the time axis. The noise of the rates is estimated
+// from the median absolute deviation of the changes of the
+// rates and the innovation of an outlier is clipped, so a
+// single outlier neither inflates the noise nor moves the slope
+// much. Rates on the same day of the time axis, e.g. a Friday
+// and a Saturday on business days, are merged into the latest
+// of them, so dt is always positive. It returns
+// ErrInsufficientData if fewer than MinDataPoints days are left.
+func (e *kalmanEngine) filter(series []model.RatePoint) (*KalmanEstimate, error) {
+	offsets, _, err := timeline(series, e.axis)
+	if err != nil {
+		return nil, err
+	}
+	days, rates := mergeSameDays(offsets, values(series))
+	if len(rates) < MinDataPoints {
+		return nil, ErrInsufficientData
+	}
+	r := observationNoise(rates)
+
+	// state [level, slope] and its covariance [[p00, p01], [p01, p11]]
+	// are initialised from the first two rates
+	dt := days[1] - days[0]
+	level, slope := rates[1], (rates[1]-rates[0])/dt
+	p00, p01, p11 := r, r/dt, 2*r/(dt*dt)
+
+	for i := 2; i < len(rates); i++ {
+		dt = days[i] - days[i-1]
+
+		// predict
+		level += dt * slope
+		p00, p01 = p00+2*dt*p01+dt*dt*p11+dt*e.levelNoise*r, p01+dt*p11
+		p11 += dt * e.slopeNoise * r
+
+		// update
+		s := p00 + r
+		k0, k1 := p00/s, p01/s
+		innovation := rates[i] - level
+		if limit := outlierThreshold * math.Sqrt(s); math.Abs(innovation) > limit {
+			innovation = math.Copysign(limit, innovation)
+		}
+		level += k0 * innovation
+		slope += k1 * innovation
+		p00, p01, p11 = (1-k0)*p00, (1-k0)*p01, p11-k1*p01
+	}
+
+	return &KalmanEstimate{
+		Level:       level,
+		Slope:       slope,
+		SlopeStdErr: math.Sqrt(math.Max(p11, 0)),
+		Points:      len(rates),
+	}, nil
+}
+
+// mergeSameDays keeps only the latest of the rates
+// on the same day of the time axis. The days must
+// be sorted.
+func mergeSameDays(days, rates []float64) ([]float64, []float64) {
+	mergedDays, mergedRates := make([]float64, 0, len(days)), make([]float64, 0, len(rates))
+	for i, d := range days {
+		if n := len(mergedDays); n > 0 && d <= mergedDays[n-1] {
+			mergedRates[n-1] = rates[i]
+			continue
+		}
+		mergedDays = append(mergedDays, d)
+		mergedRates = append(mergedRates, rates[i])
+	}
+	return mergedDays, mergedRates
+}
+
+// observationNoise estimates the variance of the noise of the
+// rates from the median absolute deviation of their changes,
+// scaled to the standard deviation of normal noise. It falls
+// back to the standard deviation of the changes if most changes
+// are the same. A change has the noise of two rates.
+func observationNoise(rates []float64) float64 {
+	changes := make([]float64, len(rates)-1)
+	for i := range changes {
+		changes[i] = rates[i+1] - rates[i]
+	}
+
+	m := median(changes)
+	deviations := make([]float64, len(changes))
+	for i, c := range changes {
+		deviations[i] = math.Abs(c - m)
+	}
+
+	std := 1.4826 * median(deviations)
+	if std*std/2 < minNoise && len(changes) > 1 {
+		std = stat.StdDev(changes, nil)
+	}
+	return math.Max(std*std/2, minNoise)
+}
+
+// median returns the median of the values
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
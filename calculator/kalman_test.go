@@ -0,0 +1,193 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKalmanFilter checks the level and slope of the
+// rates are filtered
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- level and slope are right
+func TestKalmanFilter(t *testing.T) {
+	type testParams struct {
+		description string
+		rates       []float64
+		expLevel    float64
+		expSlope    float64
+		delta       float64
+	}
+
+	cases := []testParams{
+		{
+			description: "rates rising by 0.01 every day without noise",
+			rates:       []float64{1.10, 1.11, 1.12, 1.13, 1.14, 1.15},
+			expLevel:    1.15,
+			expSlope:    0.01,
+			delta:       1e-9,
+		},
+		{
+			description: "constant rates",
+			rates:       []float64{1.10, 1.10, 1.10, 1.10},
+			expLevel:    1.10,
+			expSlope:    0,
+			delta:       1e-9,
+		},
+		{
+			description: "rates rising by 0.01 every day with noise",
+			rates:       []float64{1.10, 1.115, 1.12, 1.135, 1.14, 1.155, 1.16, 1.175, 1.18, 1.195},
+			expLevel:    1.1925,
+			expSlope:    0.01,
+			delta:       0.001,
+		},
+	}
+
+	e, err := NewKalmanEngine(DefaultKalmanLevelNoise, DefaultKalmanSlopeNoise,
+		DefaultKalmanThreshold, DefaultKalmanStrongThreshold, AxisCalendarDays)
+	assert.NoError(t, err)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			series, err := validSeries(ratesListOf(tt.rates...), EUR, MinDataPoints)
+			assert.NoError(t, err)
+
+			estimate, err := e.(*kalmanEngine).filter(series)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.expLevel, estimate.Level, tt.delta, "level is wrong")
+			assert.InDelta(t, tt.expSlope, estimate.Slope, tt.delta, "slope is wrong")
+			assert.Equal(t, len(tt.rates), estimate.Points)
+		})
+	}
+}
+
+// TestKalmanBusinessDays checks rates on the same
+// business day are merged before filtering
+// Scenario:
+// 	- rates on a Friday, the weekend and the next 3 business
+// 	  days rising by 0.01 every business day
+// 	- rates on a Friday and the weekend only
+//
+// Expect:
+// 	- the weekend rates are merged into the Friday rate
+// 	  and the slope is 0.01
+// 	- ErrInsufficientData if too few business days are left
+func TestKalmanBusinessDays(t *testing.T) {
+	e, err := NewKalmanEngine(DefaultKalmanLevelNoise, DefaultKalmanSlopeNoise,
+		DefaultKalmanThreshold, DefaultKalmanStrongThreshold, AxisBusinessDays)
+	assert.NoError(t, err)
+
+	series, err := validSeries(ratesListOf(1.10, 1.10, 1.10, 1.11, 1.12, 1.13), EUR, MinDataPoints)
+	assert.NoError(t, err)
+
+	estimate, err := e.(*kalmanEngine).filter(series)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.13, estimate.Level, 1e-9, "level is wrong")
+	assert.InDelta(t, 0.01, estimate.Slope, 1e-9, "slope is wrong")
+	assert.Equal(t, 4, estimate.Points)
+
+	_, err = e.Recommend(ratesListOf(1.10, 1.10, 1.10))
+	assert.Equal(t, ErrInsufficientData, err)
+}
+
+// TestKalmanOutlier checks a single outlier
+// barely moves the filtered slope
+// Scenario:
+// 	- rates alternate between 1.10 and 1.11
+// 	- the latest rate jumps to 1.30
+//
+// Expect:
+// 	- the trend engine recommends to wait on the slope of the outlier
+// 	- the slope of the Kalman filter is within threshold standard
+// 	  errors of 0 so it is neutral
+func TestKalmanOutlier(t *testing.T) {
+	ratesList := ratesListOf(1.10, 1.11, 1.10, 1.11, 1.10, 1.11, 1.10, 1.11, 1.10, 1.30)
+
+	recommendation, err := NewEngine().Recommend(ratesList)
+	assert.NoError(t, err)
+	assert.Equal(t, SignalNoConvert, recommendation.Signal)
+
+	e, err := NewKalmanEngine(DefaultKalmanLevelNoise, DefaultKalmanSlopeNoise,
+		DefaultKalmanThreshold, DefaultKalmanStrongThreshold, AxisCalendarDays)
+	assert.NoError(t, err)
+
+	recommendation, err = e.Recommend(ratesList)
+	assert.NoError(t, err)
+	assert.Equal(t, SignalNeutral, recommendation.Signal, "recommendation is wrong")
+}
+
+// TestKalmanRecommend checks that the right signal
+// recommendation is given based on the filtered slope
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given
+func TestKalmanRecommend(t *testing.T) {
+	type testParams struct {
+		description string
+		rates       []float64
+		expSignal   Signal
+		expStrength Strength
+		expErr      error
+	}
+
+	cases := []testParams{
+		{
+			description: "SignalNoConvert if the rate rises",
+			rates:       []float64{1.10, 1.115, 1.12, 1.135, 1.14, 1.155, 1.16, 1.175, 1.18, 1.195},
+			expSignal:   SignalNoConvert,
+			expStrength: StrengthStrong,
+		},
+		{
+			description: "SignalConvert if the rate falls",
+			rates:       []float64{1.195, 1.18, 1.175, 1.16, 1.155, 1.14, 1.135, 1.12, 1.115, 1.10},
+			expSignal:   SignalConvert,
+			expStrength: StrengthStrong,
+		},
+		{
+			description: "SignalNeutral if the rate is flat with noise",
+			rates:       []float64{1.10, 1.102, 1.099, 1.101, 1.103, 1.1, 1.098, 1.101, 1.099, 1.102},
+			expSignal:   SignalNeutral,
+		},
+		{
+			description: "SignalNeutral if the rate is constant",
+			rates:       []float64{1.10, 1.10, 1.10},
+			expSignal:   SignalNeutral,
+		},
+		{
+			description: "ErrInsufficientData if too few rates",
+			rates:       []float64{1.10, 1.15},
+			expErr:      ErrInsufficientData,
+		},
+	}
+
+	e, err := NewKalmanEngine(DefaultKalmanLevelNoise, DefaultKalmanSlopeNoise,
+		DefaultKalmanThreshold, DefaultKalmanStrongThreshold, AxisCalendarDays)
+	assert.NoError(t, err)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			recommendation, err := e.Recommend(ratesListOf(tt.rates...))
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expSignal, recommendation.Signal, "recommendation is wrong")
+			assert.Equal(t, tt.expStrength, recommendation.Strength, "strength is wrong")
+		})
+	}
+}
+
+// TestNewKalmanEngineInvalid checks invalid
+// noise and thresholds are rejected
+func TestNewKalmanEngineInvalid(t *testing.T) {
+	_, err := NewKalmanEngine(-1, DefaultKalmanSlopeNoise, 1, 2, AxisCalendarDays)
+	assert.Error(t, err)
+
+	_, err = NewKalmanEngine(DefaultKalmanLevelNoise, DefaultKalmanSlopeNoise, 2, 1, AxisCalendarDays)
+	assert.Error(t, err)
+
+	_, err = NewKalmanEngine(DefaultKalmanLevelNoise, DefaultKalmanSlopeNoise, 1, 2, TimeAxis("hours"))
+	assert.Error(t, err)
+}
@@ -36,11 +36,12 @@ func (m *MockEngine) EXPECT() *MockEngineMockRecorder {
 }
 
 // Recommend mocks base method
-func (m *MockEngine) Recommend(arg0 model.RatesList) calculator.Signal {
+func (m *MockEngine) Recommend(arg0 model.RatesList) (calculator.Recommendation, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Recommend", arg0)
-	ret0, _ := ret[0].(calculator.Signal)
-	return ret0
+	ret0, _ := ret[0].(calculator.Recommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Recommend indicates an expected call of Recommend
@@ -0,0 +1,215 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/jeffreyyong/xe/model"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	SimulationGBM       SimulationMethod = "gbm"
+	SimulationBootstrap SimulationMethod = "bootstrap"
+
+	// DefaultSimulationDays is the default number
+	// of business days simulated
+	DefaultSimulationDays = 5
+
+	// DefaultSimulationPaths is the default
+	// number of simulated paths of the rate
+	DefaultSimulationPaths = 1000
+
+	// DefaultSimulationSeed seeds the random numbers
+	// so simulations of the same rates are reproducible
+	DefaultSimulationSeed = 1
+
+	// DefaultSimulationThreshold is the default probability
+	// of a better rate beyond which waiting is recommended
+	DefaultSimulationThreshold = 0.6
+)
+
+// SimulationQuantiles are the quantiles
+// of the simulated rate on the last day
+var SimulationQuantiles = []float64{0.05, 0.25, 0.5, 0.75, 0.95}
+
+// SimulationMethod is how the paths of the rate are simulated
+type SimulationMethod string
+
+// SimulationConfig describes how the future
+// rates are simulated
+type SimulationConfig struct {
+	Method SimulationMethod
+	Days   int
+	Paths  int
+	Seed   int64
+}
+
+// Quantile is the rate the simulated
+// rate is at or below with probability Q
+type Quantile struct {
+	Q    float64
+	Rate float64
+}
+
+// Simulation is the distribution of the simulated rates
+type Simulation struct {
+	// Drift and Volatility are the mean and standard
+	// deviation of the daily log returns of the rates
+	Drift      float64
+	Volatility float64
+
+	// ProbabilityBetter is the probability the rate is above the
+	// latest rate on the last day, ProbabilityBetterWithin the
+	// probability it is above the latest rate on any day
+	ProbabilityBetter       float64
+	ProbabilityBetterWithin float64
+
+	// ProbabilityWorse is the probability the rate is
+	// below the latest rate on the last day, paths that
+	// end at the latest rate are neither better nor worse
+	ProbabilityWorse float64
+
+	Quantiles []Quantile
+}
+
+// ParseSimulationMethod parses the simulation method,
+// it defaults to gbm if s is empty
+func ParseSimulationMethod(s string) (SimulationMethod, error) {
+	switch m := SimulationMethod(s); m {
+	case "":
+		return SimulationGBM, nil
+	case SimulationGBM, SimulationBootstrap:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown simulation method: %s", s)
+	}
+}
+
+// Validate checks the simulation can be run
+func (c SimulationConfig) Validate() error {
+	if _, err := ParseSimulationMethod(string(c.Method)); err != nil {
+		return err
+	}
+	if c.Days < 1 || c.Paths < 1 {
+		return fmt.Errorf("days and paths must be positive, got %d and %d", c.Days, c.Paths)
+	}
+	return nil
+}
+
+// Simulate:
+// 1) computes the daily log returns of the rates and fits
+//    their drift and volatility
+// 2) simulates c.Paths paths of the rate over c.Days business
+//    days from the latest rate, either as geometric Brownian
+//    motion with the drift and volatility, or by bootstrapping,
+//    i.e. drawing the daily returns from the historical returns
+// 3) counts the paths better and worse than the latest rate and
+//    computes the quantiles of the rate on the last day
+// The random numbers are seeded with c.Seed.
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates and a SeriesError if a rate is invalid.
+func Simulate(ratesList model.RatesList, c SimulationConfig) (*Simulation, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	series, err := validSeries(ratesList, EUR, MinDataPoints)
+	if err != nil {
+		return nil, err
+	}
+	rates := values(series)
+	latest := rates[len(rates)-1]
+
+	returns := logReturns(rates)
+	drift, volatility := stat.MeanStdDev(returns, nil)
+
+	rnd := rand.New(rand.NewSource(c.Seed))
+	step := func() float64 {
+		if c.Method == SimulationBootstrap {
+			return returns[rnd.Intn(len(returns))]
+		}
+		return drift + volatility*rnd.NormFloat64()
+	}
+
+	last := make([]float64, c.Paths)
+	better, betterWithin, worse := 0, 0, 0
+	for i := range last {
+		logRate, within := math.Log(latest), false
+		for d := 0; d < c.Days; d++ {
+			logRate += step()
+			within = within || logRate > math.Log(latest)
+		}
+
+		last[i] = math.Exp(logRate)
+		switch {
+		case logRate > math.Log(latest):
+			better++
+		case logRate < math.Log(latest):
+			worse++
+		}
+		if within {
+			betterWithin++
+		}
+	}
+
+	sort.Float64s(last)
+	quantiles := make([]Quantile, len(SimulationQuantiles))
+	for i, q := range SimulationQuantiles {
+		quantiles[i] = Quantile{Q: q, Rate: stat.Quantile(q, stat.Empirical, last, nil)}
+	}
+
+	return &Simulation{
+		Drift:                   drift,
+		Volatility:              volatility,
+		ProbabilityBetter:       float64(better) / float64(c.Paths),
+		ProbabilityBetterWithin: float64(betterWithin) / float64(c.Paths),
+		ProbabilityWorse:        float64(worse) / float64(c.Paths),
+		Quantiles:               quantiles,
+	}, nil
+}
+
+type monteCarloEngine struct {
+	config    SimulationConfig
+	threshold float64
+}
+
+// NewMonteCarloEngine initialises the calculator engine that
+// recommends on the probability of a better rate in the
+// simulated paths
+func NewMonteCarloEngine(c SimulationConfig, threshold float64) (Engine, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if threshold <= 0.5 || threshold >= 1 {
+		return nil, fmt.Errorf("threshold must be between 0.5 and 1, got %v", threshold)
+	}
+
+	return &monteCarloEngine{
+		config:    c,
+		threshold: threshold,
+	}, nil
+}
+
+// Recommend:
+// 1) simulates the paths of the rate from the rates
+// 2) returns 'don't convert' if the rate is better than the
+//    latest rate on the last day with at least the threshold
+//    probability, 'convert' if it is worse with at least the
+//    threshold probability, and 'neutral' otherwise.
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates.
+func (e *monteCarloEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	simulation, err := Simulate(ratesList, e.config)
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	if simulation.ProbabilityBetter >= e.threshold {
+		return Recommendation{Signal: SignalNoConvert}, nil
+	} else if simulation.ProbabilityWorse >= e.threshold {
+		return Recommendation{Signal: SignalConvert}, nil
+	}
+	return Recommendation{Signal: SignalNeutral}, nil
+}
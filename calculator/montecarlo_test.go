@@ -0,0 +1,163 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSimulateBootstrap checks the paths are drawn
+// from the historical returns
+// Scenario:
+// 	- rates rise by 10% every day
+// 	- bootstrap of 2 days
+//
+// Expect:
+// 	- every path rises by 10% every day
+func TestSimulateBootstrap(t *testing.T) {
+	c := SimulationConfig{Method: SimulationBootstrap, Days: 2, Paths: 100, Seed: DefaultSimulationSeed}
+	simulation, err := Simulate(ratesListOf(1, 1.1, 1.21), c)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, math.Log(1.1), simulation.Drift, 1e-12, "drift is wrong")
+	assert.InDelta(t, 0, simulation.Volatility, 1e-12, "volatility is wrong")
+	assert.Equal(t, 1.0, simulation.ProbabilityBetter)
+	assert.Equal(t, 1.0, simulation.ProbabilityBetterWithin)
+	assert.Equal(t, 0.0, simulation.ProbabilityWorse)
+	assert.Len(t, simulation.Quantiles, len(SimulationQuantiles))
+	for i, q := range simulation.Quantiles {
+		assert.Equal(t, SimulationQuantiles[i], q.Q)
+		assert.InDelta(t, 1.4641, q.Rate, 1e-9, "quantile is wrong")
+	}
+}
+
+// TestSimulateGBM checks the distribution of the
+// paths of geometric Brownian motion
+// Scenario:
+// 	- rates alternate between 1.10 and 1.12, i.e. no drift
+// 	- 10000 paths of 5 days
+//
+// Expect:
+// 	- rate is better on the last day about half of the time
+// 	- rate is better on some day more often than on the last day
+// 	- median is about the latest rate
+// 	- same seed gives the same simulation
+func TestSimulateGBM(t *testing.T) {
+	ratesList := ratesListOf(1.10, 1.12, 1.10, 1.12, 1.10)
+	c := SimulationConfig{Method: SimulationGBM, Days: 5, Paths: 10000, Seed: DefaultSimulationSeed}
+
+	simulation, err := Simulate(ratesList, c)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, simulation.Drift, 1e-12, "drift is wrong")
+	assert.InDelta(t, 0.5, simulation.ProbabilityBetter, 0.02)
+	assert.True(t, simulation.ProbabilityBetterWithin > simulation.ProbabilityBetter)
+	assert.InDelta(t, 1.10, simulation.Quantiles[2].Rate, 0.002, "median is wrong")
+	assert.True(t, simulation.Quantiles[0].Rate < simulation.Quantiles[4].Rate)
+
+	again, err := Simulate(ratesList, c)
+	assert.NoError(t, err)
+	assert.Equal(t, simulation, again)
+}
+
+// TestSimulateConstant checks a constant rate is
+// neither better nor worse in the simulated paths
+func TestSimulateConstant(t *testing.T) {
+	ratesList := ratesListOf(1.10, 1.10, 1.10, 1.10, 1.10)
+	c := SimulationConfig{Method: SimulationGBM, Days: 5, Paths: 100, Seed: DefaultSimulationSeed}
+
+	simulation, err := Simulate(ratesList, c)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, simulation.ProbabilityBetter)
+	assert.Equal(t, 0.0, simulation.ProbabilityWorse)
+}
+
+// TestSimulateError checks the rates
+// and config are validated
+func TestSimulateError(t *testing.T) {
+	c := SimulationConfig{Method: SimulationGBM, Days: 5, Paths: 100}
+
+	_, err := Simulate(ratesListOf(1.10, 1.12), c)
+	assert.Equal(t, ErrInsufficientData, err)
+
+	c.Method = "heston"
+	_, err = Simulate(ratesListOf(1.10, 1.12, 1.11), c)
+	assert.Error(t, err)
+
+	c.Method, c.Paths = SimulationBootstrap, 0
+	_, err = Simulate(ratesListOf(1.10, 1.12, 1.11), c)
+	assert.Error(t, err)
+}
+
+// TestMonteCarloRecommend checks that the right signal
+// recommendation is given based on the simulation
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given
+func TestMonteCarloRecommend(t *testing.T) {
+	type testParams struct {
+		description       string
+		rates             []float64
+		expRecommendation Signal
+		expErr            error
+	}
+
+	cases := []testParams{
+		{
+			description:       "SignalNoConvert if the rate is likely to rise",
+			rates:             []float64{1.10, 1.11, 1.12, 1.13, 1.14},
+			expRecommendation: SignalNoConvert,
+		},
+		{
+			description:       "SignalConvert if the rate is likely to fall",
+			rates:             []float64{1.14, 1.13, 1.12, 1.11, 1.10},
+			expRecommendation: SignalConvert,
+		},
+		{
+			description:       "SignalNeutral if the rate is as likely to rise as to fall",
+			rates:             []float64{1.10, 1.12, 1.10, 1.12, 1.10},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description:       "SignalNeutral if the rate is constant",
+			rates:             []float64{1.10, 1.10, 1.10, 1.10, 1.10},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description: "ErrInsufficientData if too few rates",
+			rates:       []float64{1.10, 1.15},
+			expErr:      ErrInsufficientData,
+		},
+	}
+
+	e, err := NewMonteCarloEngine(DefaultStrategyParams().Simulation, DefaultSimulationThreshold)
+	assert.NoError(t, err)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			recommendation, err := e.Recommend(ratesListOf(tt.rates...))
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expRecommendation, recommendation.Signal,
+				"recommendation is wrong")
+		})
+	}
+
+	_, err = NewMonteCarloEngine(DefaultStrategyParams().Simulation, 0.5)
+	assert.Error(t, err)
+}
+
+// TestParseSimulationMethod checks that only known methods are accepted
+func TestParseSimulationMethod(t *testing.T) {
+	method, err := ParseSimulationMethod("")
+	assert.NoError(t, err)
+	assert.Equal(t, SimulationGBM, method)
+
+	method, err = ParseSimulationMethod("bootstrap")
+	assert.NoError(t, err)
+	assert.Equal(t, SimulationBootstrap, method)
+
+	_, err = ParseSimulationMethod("heston")
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	AverageSimple      Average = "sma"
+	AverageExponential Average = "ema"
+
+	// DefaultShortPeriod is the default number of
+	// rates in the short moving average
+	DefaultShortPeriod = 5
+
+	// DefaultLongPeriod is the default number of
+	// rates in the long moving average
+	DefaultLongPeriod = 20
+)
+
+// Average is the custom string for the
+// type of moving average
+type Average string
+
+type movingAverageEngine struct {
+	short   int
+	long    int
+	average Average
+}
+
+// NewMovingAverageEngine initialises the calculator engine
+// that recommends on crossovers of a short and a long
+// moving average of the rates
+func NewMovingAverageEngine(short, long int, average Average) (Engine, error) {
+	if short < 1 || long <= short {
+		return nil, fmt.Errorf("periods must satisfy 0 < short < long, got short %d long %d", short, long)
+	}
+	if average != AverageSimple && average != AverageExponential {
+		return nil, fmt.Errorf("unknown moving average: %s", average)
+	}
+
+	return &movingAverageEngine{
+		short:   short,
+		long:    long,
+		average: average,
+	}, nil
+}
+
+// Recommend:
+// 1) takes a list of rates and orders them by date
+// 2) computes the short and long moving averages
+// 3) finds the latest crossover of the two averages
+// 4) returns 'convert' if the short average crossed below
+//    the long one, i.e. the price is getting cheaper, returns
+//    'don't convert' if it crossed above, and 'neutral' if
+//    the averages haven't crossed.
+// It returns ErrInsufficientData if there are too few rates
+// for the long average to be compared on two days.
+func (e *movingAverageEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, e.long+1)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	rates := values(series)
+
+	shortMA := e.movingAverage(rates, e.short)
+	longMA := e.movingAverage(rates, e.long)
+
+	// align both averages to the dates of the long one
+	offset := e.long - e.short
+	diffs := make([]float64, len(longMA))
+	for i := range longMA {
+		diffs[i] = shortMA[i+offset] - longMA[i]
+	}
+
+	switch latestCrossover(diffs) {
+	case 1:
+		return Recommendation{Signal: SignalNoConvert}, nil
+	case -1:
+		return Recommendation{Signal: SignalConvert}, nil
+	default:
+		return Recommendation{Signal: SignalNeutral}, nil
+	}
+}
+
+func (e *movingAverageEngine) movingAverage(rates []float64, period int) []float64 {
+	if e.average == AverageExponential {
+		return ema(rates, period)
+	}
+	return sma(rates, period)
+}
+
+// latestCrossover returns the direction of the latest sign change
+// of the differences between the short and long averages, i.e.
+// 1 if the short average crossed above, -1 if it crossed below
+// and 0 if they haven't crossed
+func latestCrossover(diffs []float64) int {
+	for i := len(diffs) - 1; i > 0; i-- {
+		cur, prev := sign(diffs[i]), sign(diffs[i-1])
+		if cur != 0 && cur != prev {
+			return cur
+		}
+	}
+	return 0
+}
+
+// sma computes the simple moving average of the rates,
+// the i-th average ends on the (i+period-1)-th rate
+func sma(rates []float64, period int) []float64 {
+	if len(rates) < period {
+		return nil
+	}
+
+	averages := make([]float64, len(rates)-period+1)
+	sum := 0.0
+	for i, r := range rates {
+		sum += r
+		if i >= period {
+			sum -= rates[i-period]
+		}
+		if i >= period-1 {
+			averages[i-period+1] = sum / float64(period)
+		}
+	}
+	return averages
+}
+
+// ema computes the exponential moving average of the rates
+// with the smoothing factor 2/(period+1), seeded with the
+// simple average of the first period rates. It is aligned
+// the same way as sma.
+func ema(rates []float64, period int) []float64 {
+	if len(rates) < period {
+		return nil
+	}
+
+	alpha := 2 / float64(period+1)
+	averages := make([]float64, len(rates)-period+1)
+	averages[0] = sma(rates[:period], period)[0]
+	for i := 1; i < len(averages); i++ {
+		averages[i] = alpha*rates[i+period-1] + (1-alpha)*averages[i-1]
+	}
+	return averages
+}
+
+func sign(f float64) int {
+	if f > 0 {
+		return 1
+	} else if f < 0 {
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSMA checks the simple moving average is
+// computed over a rolling period
+func TestSMA(t *testing.T) {
+	rates := []float64{1, 2, 3, 4, 5}
+	assert.InDeltaSlice(t, []float64{2, 3, 4}, sma(rates, 3), 1e-12)
+	assert.Nil(t, sma(rates, 6))
+}
+
+// TestEMA checks the exponential moving average is seeded
+// with the simple average and smoothed with 2/(period+1)
+func TestEMA(t *testing.T) {
+	rates := []float64{1, 2, 3, 4, 5}
+
+	// seed (1+2+3)/3 = 2, alpha = 0.5
+	// 0.5*4 + 0.5*2 = 3
+	// 0.5*5 + 0.5*3 = 4
+	assert.InDeltaSlice(t, []float64{2, 3, 4}, ema(rates, 3), 1e-12)
+}
+
+// TestNewMovingAverageEngineInvalid checks invalid
+// periods and averages are rejected
+func TestNewMovingAverageEngineInvalid(t *testing.T) {
+	_, err := NewMovingAverageEngine(5, 5, AverageSimple)
+	assert.Error(t, err)
+
+	_, err = NewMovingAverageEngine(0, 5, AverageSimple)
+	assert.Error(t, err)
+
+	_, err = NewMovingAverageEngine(2, 5, Average("wma"))
+	assert.Error(t, err)
+}
+
+// TestMovingAverageRecommend checks that the right signal
+// recommendation is given based on the crossovers
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given for both averages
+func TestMovingAverageRecommend(t *testing.T) {
+	type testParams struct {
+		description       string
+		rates             []float64
+		expRecommendation Signal
+		expErr            error
+	}
+
+	cases := []testParams{
+		{
+			description:       "SignalConvert if short average crosses below",
+			rates:             []float64{1.10, 1.11, 1.12, 1.13, 1.14, 1.13, 1.11, 1.09, 1.07},
+			expRecommendation: SignalConvert,
+		},
+		{
+			description:       "SignalNoConvert if short average crosses above",
+			rates:             []float64{1.14, 1.13, 1.12, 1.11, 1.10, 1.11, 1.13, 1.15, 1.17},
+			expRecommendation: SignalNoConvert,
+		},
+		{
+			description:       "SignalNeutral if averages don't cross",
+			rates:             []float64{1.10, 1.11, 1.12, 1.13, 1.14, 1.15, 1.16, 1.17, 1.18},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description: "ErrInsufficientData if too few rates for the long average",
+			rates:       []float64{1.10, 1.11, 1.12, 1.13},
+			expErr:      ErrInsufficientData,
+		},
+	}
+
+	for _, average := range []Average{AverageSimple, AverageExponential} {
+		e, err := NewMovingAverageEngine(2, 4, average)
+		assert.NoError(t, err)
+
+		for _, tt := range cases {
+			t.Run(string(average)+": "+tt.description, func(t *testing.T) {
+				recommendation, err := e.Recommend(ratesListOf(tt.rates...))
+				assert.Equal(t, tt.expErr, err, "error is wrong")
+				assert.Equal(t, tt.expRecommendation, recommendation.Signal,
+					"recommendation is wrong")
+			})
+		}
+	}
+}
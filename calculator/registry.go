@@ -0,0 +1,351 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jeffreyyong/xe/date"
+)
+
+const (
+	StrategyTrend         = "trend"
+	StrategyMovingAverage = "moving-average"
+	StrategyZScore        = "zscore"
+	StrategyRSI           = "rsi"
+	StrategyBollinger     = "bollinger"
+	StrategyForecast      = "forecast"
+	StrategyMonteCarlo    = "monte-carlo"
+	StrategyKalman        = "kalman"
+)
+
+// Params is a map of parameter:value
+// describing how a strategy is tuned
+type Params map[string]interface{}
+
+// Strategy is an Engine registered under a name
+type Strategy struct {
+	Name        string
+	Description string
+	Params      Params
+	Engine      Engine
+
+	// MinRates is the number of rates the
+	// Engine needs to recommend, 0 if unknown
+	MinRates int
+}
+
+// Window returns the number of days of rates to fetch
+// for the strategy, days widened if needed to span the
+// rates it needs to recommend
+func (s Strategy) Window(days int) int {
+	if min := date.DaysSpanning(s.MinRates); min > days {
+		return min
+	}
+	return days
+}
+
+// StrategyParams holds the tunable parameters
+// of the built-in strategies
+type StrategyParams struct {
+	TrendThresholdMode   ThresholdMode
+	TrendThreshold       float64
+	TrendStrongThreshold float64
+	TrendAxis            TimeAxis
+	ShortPeriod          int
+	LongPeriod           int
+	Average              Average
+	ZScoreThreshold      float64
+	RSIPeriod            int
+	RSIOverbought        float64
+	RSIOversold          float64
+	BollingerPeriod      int
+	BollingerWidth       float64
+	ForecastMethod       ForecastMethod
+	ForecastDays         int
+	ForecastConfidence   float64
+	HoltAlpha            float64
+	HoltBeta             float64
+	AROrder              int
+	Simulation           SimulationConfig
+	SimulationThreshold  float64
+	KalmanLevelNoise     float64
+	KalmanSlopeNoise     float64
+	KalmanThreshold      float64
+	KalmanStrong         float64
+	EnsembleVote         VoteMode
+
+	// EnsembleWeights is a map of strategy:weight of the
+	// ensemble members, members default to a weight of 1
+	EnsembleWeights map[string]float64
+}
+
+// DefaultStrategyParams returns the default parameters
+// of the built-in strategies
+func DefaultStrategyParams() StrategyParams {
+	return StrategyParams{
+		TrendThresholdMode:   ThresholdRelative,
+		TrendThreshold:       DefaultTrendThreshold,
+		TrendStrongThreshold: DefaultStrongThreshold,
+		TrendAxis:            AxisCalendarDays,
+		ShortPeriod:          DefaultShortPeriod,
+		LongPeriod:           DefaultLongPeriod,
+		Average:              AverageSimple,
+		ZScoreThreshold:      DefaultZScoreThreshold,
+		RSIPeriod:            DefaultRSIPeriod,
+		RSIOverbought:        DefaultRSIOverbought,
+		RSIOversold:          DefaultRSIOversold,
+		BollingerPeriod:      DefaultBollingerPeriod,
+		BollingerWidth:       DefaultBollingerWidth,
+		ForecastMethod:       ForecastHolt,
+		ForecastDays:         DefaultForecastDays,
+		ForecastConfidence:   DefaultConfidence,
+		HoltAlpha:            DefaultHoltAlpha,
+		HoltBeta:             DefaultHoltBeta,
+		AROrder:              DefaultAROrder,
+		Simulation: SimulationConfig{
+			Method: SimulationGBM,
+			Days:   DefaultSimulationDays,
+			Paths:  DefaultSimulationPaths,
+			Seed:   DefaultSimulationSeed,
+		},
+		SimulationThreshold: DefaultSimulationThreshold,
+		KalmanLevelNoise:    DefaultKalmanLevelNoise,
+		KalmanSlopeNoise:    DefaultKalmanSlopeNoise,
+		KalmanThreshold:     DefaultKalmanThreshold,
+		KalmanStrong:        DefaultKalmanStrongThreshold,
+		EnsembleVote:        VoteMajority,
+	}
+}
+
+// Registry is a set of strategies by name
+type Registry struct {
+	strategies map[string]Strategy
+}
+
+// NewRegistry initialises an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		strategies: map[string]Strategy{},
+	}
+}
+
+// NewStrategies initialises a Registry with the
+// built-in strategies tuned with the given parameters
+func NewStrategies(p StrategyParams) (*Registry, error) {
+	trend, err := NewTrendEngine(p.TrendThresholdMode, p.TrendThreshold, p.TrendStrongThreshold, p.TrendAxis)
+	if err != nil {
+		return nil, err
+	}
+	ma, err := NewMovingAverageEngine(p.ShortPeriod, p.LongPeriod, p.Average)
+	if err != nil {
+		return nil, err
+	}
+	zScore, err := NewZScoreEngine(p.ZScoreThreshold)
+	if err != nil {
+		return nil, err
+	}
+	rsi, err := NewRSIEngine(p.RSIPeriod, p.RSIOverbought, p.RSIOversold)
+	if err != nil {
+		return nil, err
+	}
+	bollinger, err := NewBollingerEngine(p.BollingerPeriod, p.BollingerWidth)
+	if err != nil {
+		return nil, err
+	}
+	forecasters, err := NewForecasters(p)
+	if err != nil {
+		return nil, err
+	}
+	forecaster, ok := forecasters[p.ForecastMethod]
+	if !ok {
+		return nil, fmt.Errorf("unknown forecast method: %s", p.ForecastMethod)
+	}
+	forecast, err := NewForecastEngine(forecaster, p.ForecastDays)
+	if err != nil {
+		return nil, err
+	}
+	monteCarlo, err := NewMonteCarloEngine(p.Simulation, p.SimulationThreshold)
+	if err != nil {
+		return nil, err
+	}
+	kalman, err := NewKalmanEngine(p.KalmanLevelNoise, p.KalmanSlopeNoise, p.KalmanThreshold, p.KalmanStrong, p.TrendAxis)
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := []Strategy{
+		{
+			Name:        StrategyTrend,
+			Description: "slope of the linear regression of the rates",
+			Params: Params{
+				"threshold_mode":   p.TrendThresholdMode,
+				"threshold":        p.TrendThreshold,
+				"strong_threshold": p.TrendStrongThreshold,
+				"time_axis":        p.TrendAxis,
+			},
+			Engine:   trend,
+			MinRates: MinDataPoints,
+		},
+		{
+			Name:        StrategyMovingAverage,
+			Description: "latest crossover of a short and a long moving average of the rates",
+			Params: Params{
+				"short_period": p.ShortPeriod,
+				"long_period":  p.LongPeriod,
+				"average":      p.Average,
+			},
+			Engine:   ma,
+			MinRates: p.LongPeriod + 1,
+		},
+		{
+			Name:        StrategyZScore,
+			Description: "z-score of the latest rate against the mean of the rates",
+			Params: Params{
+				"threshold": p.ZScoreThreshold,
+			},
+			Engine:   zScore,
+			MinRates: MinDataPoints,
+		},
+		{
+			Name:        StrategyRSI,
+			Description: "Wilder's Relative Strength Index of the rates",
+			Params: Params{
+				"period":     p.RSIPeriod,
+				"overbought": p.RSIOverbought,
+				"oversold":   p.RSIOversold,
+			},
+			Engine:   rsi,
+			MinRates: p.RSIPeriod + 1,
+		},
+		{
+			Name:        StrategyBollinger,
+			Description: "latest rate against the Bollinger bands of the rates",
+			Params: Params{
+				"period": p.BollingerPeriod,
+				"width":  p.BollingerWidth,
+			},
+			Engine:   bollinger,
+			MinRates: p.BollingerPeriod,
+		},
+		{
+			Name:        StrategyForecast,
+			Description: "forecast of the rates in the coming business days",
+			Params: Params{
+				"method":     p.ForecastMethod,
+				"days":       p.ForecastDays,
+				"confidence": p.ForecastConfidence,
+				"alpha":      p.HoltAlpha,
+				"beta":       p.HoltBeta,
+				"order":      p.AROrder,
+			},
+			Engine:   forecast,
+			MinRates: forecastMinRates(forecaster),
+		},
+		{
+			Name:        StrategyMonteCarlo,
+			Description: "probability of a better rate in Monte Carlo simulations of the rates",
+			Params: Params{
+				"method":    p.Simulation.Method,
+				"days":      p.Simulation.Days,
+				"paths":     p.Simulation.Paths,
+				"seed":      p.Simulation.Seed,
+				"threshold": p.SimulationThreshold,
+			},
+			Engine:   monteCarlo,
+			MinRates: MinDataPoints,
+		},
+		{
+			Name:        StrategyKalman,
+			Description: "slope of the rates filtered by a Kalman filter",
+			Params: Params{
+				"level_noise":      p.KalmanLevelNoise,
+				"slope_noise":      p.KalmanSlopeNoise,
+				"threshold":        p.KalmanThreshold,
+				"strong_threshold": p.KalmanStrong,
+				"time_axis":        p.TrendAxis,
+			},
+			Engine:   kalman,
+			MinRates: MinDataPoints,
+		},
+	}
+
+	// every other strategy is a member of the ensemble,
+	// it needs the rates of the member needing the most
+	members := make([]Member, len(strategies))
+	weights := map[string]float64{}
+	minRates := 0
+	for i, s := range strategies {
+		if s.MinRates > minRates {
+			minRates = s.MinRates
+		}
+		weight := 1.0
+		if w, ok := p.EnsembleWeights[s.Name]; ok {
+			weight = w
+		}
+		members[i] = Member{Name: s.Name, Engine: s.Engine, Weight: weight}
+		weights[s.Name] = weight
+	}
+	ensemble, err := NewEnsembleEngine(p.EnsembleVote, members...)
+	if err != nil {
+		return nil, err
+	}
+	strategies = append(strategies, Strategy{
+		Name:        StrategyEnsemble,
+		Description: string(p.EnsembleVote) + " vote of the other strategies",
+		Params: Params{
+			"vote":    p.EnsembleVote,
+			"weights": weights,
+		},
+		Engine:   ensemble,
+		MinRates: minRates,
+	})
+
+	r := NewRegistry()
+	for _, s := range strategies {
+		if err := r.Register(s); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// forecastMinRates returns the number of rates
+// the forecast strategy needs with the forecaster
+func forecastMinRates(f Forecaster) int {
+	if n := f.MinRates(); n > MinDataPoints {
+		return n
+	}
+	return MinDataPoints
+}
+
+// Register adds the strategy to the Registry,
+// names must be unique
+func (r *Registry) Register(s Strategy) error {
+	if s.Name == "" || s.Engine == nil {
+		return fmt.Errorf("strategy must have a name and an engine")
+	}
+	if _, ok := r.strategies[s.Name]; ok {
+		return fmt.Errorf("strategy already registered: %s", s.Name)
+	}
+
+	r.strategies[s.Name] = s
+	return nil
+}
+
+// Get returns the strategy registered under the name
+func (r *Registry) Get(name string) (Strategy, bool) {
+	s, ok := r.strategies[name]
+	return s, ok
+}
+
+// List returns the registered strategies sorted by name
+func (r *Registry) List() []Strategy {
+	strategies := make([]Strategy, 0, len(r.strategies))
+	for _, s := range r.strategies {
+		strategies = append(strategies, s)
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Name < strategies[j].Name
+	})
+	return strategies
+}
@@ -0,0 +1,80 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewStrategies checks the built-in strategies
+// are registered with their parameters
+func TestNewStrategies(t *testing.T) {
+	r, err := NewStrategies(DefaultStrategyParams())
+	assert.NoError(t, err)
+
+	var names []string
+	for _, s := range r.List() {
+		names = append(names, s.Name)
+	}
+	expNames := []string{StrategyBollinger, StrategyEnsemble, StrategyForecast, StrategyKalman,
+		StrategyMonteCarlo, StrategyMovingAverage, StrategyRSI, StrategyTrend, StrategyZScore}
+	assert.Equal(t, expNames, names, "strategies are wrong")
+
+	rsi, ok := r.Get(StrategyRSI)
+	assert.True(t, ok)
+	assert.Equal(t, DefaultRSIPeriod, rsi.Params["period"])
+	assert.Equal(t, DefaultRSIPeriod+1, rsi.MinRates)
+
+	ensemble, ok := r.Get(StrategyEnsemble)
+	assert.True(t, ok)
+	assert.Equal(t, DefaultLongPeriod+1, ensemble.MinRates)
+	assert.Equal(t, 32, ensemble.Window(7))
+	assert.Equal(t, 40, ensemble.Window(40))
+	assert.Equal(t, "majority vote of the other strategies", ensemble.Description)
+
+	_, ok = r.Get("unknown")
+	assert.False(t, ok)
+}
+
+// TestNewStrategiesWeightedEnsemble checks the
+// ensemble is described by its vote mode
+func TestNewStrategiesWeightedEnsemble(t *testing.T) {
+	p := DefaultStrategyParams()
+	p.EnsembleVote = VoteWeighted
+
+	r, err := NewStrategies(p)
+	assert.NoError(t, err)
+	ensemble, ok := r.Get(StrategyEnsemble)
+	assert.True(t, ok)
+	assert.Equal(t, "weighted vote of the other strategies", ensemble.Description)
+}
+
+// TestNewStrategiesInvalidParams checks invalid
+// parameters are rejected
+func TestNewStrategiesInvalidParams(t *testing.T) {
+	p := DefaultStrategyParams()
+	p.LongPeriod = p.ShortPeriod
+
+	_, err := NewStrategies(p)
+	assert.Error(t, err)
+
+	// every member of a weighted ensemble has a weight of 0
+	p = DefaultStrategyParams()
+	p.EnsembleVote = VoteWeighted
+	p.EnsembleWeights = map[string]float64{}
+	r, err := NewStrategies(DefaultStrategyParams())
+	assert.NoError(t, err)
+	for _, s := range r.List() {
+		p.EnsembleWeights[s.Name] = 0
+	}
+	_, err = NewStrategies(p)
+	assert.Error(t, err)
+}
+
+// TestRegisterDuplicate checks strategy names are unique
+func TestRegisterDuplicate(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.Register(Strategy{Name: StrategyTrend, Engine: NewEngine()}))
+	assert.Error(t, r.Register(Strategy{Name: StrategyTrend, Engine: NewEngine()}))
+	assert.Error(t, r.Register(Strategy{Name: "no engine"}))
+}
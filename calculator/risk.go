@@ -0,0 +1,98 @@
+package calculator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/jeffreyyong/xe/model"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	// TradingDaysPerYear annualises the daily volatility
+	TradingDaysPerYear = 252
+
+	// DefaultVaRConfidence is the default probability
+	// the daily loss is within the Value-at-Risk
+	DefaultVaRConfidence = 0.95
+)
+
+// Risk holds the volatility and risk metrics of the rates,
+// losses are falls of the rate, i.e. 1 currency buys fewer euros
+type Risk struct {
+	// Returns is the number of daily log returns
+	Returns int
+
+	// Volatility is the sample standard deviation of
+	// the daily log returns, AnnualisedVolatility scales
+	// it by the square root of TradingDaysPerYear
+	Volatility           float64
+	AnnualisedVolatility float64
+
+	// MaxDrawdown is the largest fall of the rate
+	// from a previous peak as a fraction of the peak
+	MaxDrawdown float64
+
+	// ValueAtRisk is the historical daily loss as a
+	// fraction of the rate which is not exceeded
+	// with the probability of the confidence
+	ValueAtRisk float64
+	Confidence  float64
+}
+
+// RiskMetrics:
+// 1) computes the log returns between consecutive rates
+// 2) computes the volatility as the standard deviation of the
+//    returns and annualises it
+// 3) computes the max drawdown of the rates
+// 4) computes the historical Value-at-Risk as the loss of the
+//    (1 - confidence) empirical quantile of the returns
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates and a SeriesError if a rate is invalid.
+func RiskMetrics(ratesList model.RatesList, confidence float64) (*Risk, error) {
+	if err := validConfidence(confidence); err != nil {
+		return nil, err
+	}
+	series, err := validSeries(ratesList, EUR, MinDataPoints)
+	if err != nil {
+		return nil, err
+	}
+	rates := values(series)
+
+	returns := logReturns(rates)
+	volatility := stat.StdDev(returns, nil)
+
+	sorted := append([]float64{}, returns...)
+	sort.Float64s(sorted)
+	quantile := stat.Quantile(1-confidence, stat.Empirical, sorted, nil)
+
+	return &Risk{
+		Returns:              len(returns),
+		Volatility:           volatility,
+		AnnualisedVolatility: volatility * math.Sqrt(TradingDaysPerYear),
+		MaxDrawdown:          maxDrawdown(rates),
+		ValueAtRisk:          math.Max(0, -math.Expm1(quantile)),
+		Confidence:           confidence,
+	}, nil
+}
+
+// logReturns returns the log of the
+// ratio of consecutive rates
+func logReturns(rates []float64) []float64 {
+	returns := make([]float64, len(rates)-1)
+	for i := range returns {
+		returns[i] = math.Log(rates[i+1] / rates[i])
+	}
+	return returns
+}
+
+// maxDrawdown returns the largest fall of the rates
+// from a previous peak as a fraction of the peak
+func maxDrawdown(rates []float64) float64 {
+	peak, drawdown := rates[0], 0.0
+	for _, r := range rates {
+		peak = math.Max(peak, r)
+		drawdown = math.Max(drawdown, (peak-r)/peak)
+	}
+	return drawdown
+}
@@ -0,0 +1,89 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRiskMetrics checks the volatility, drawdown
+// and Value-at-Risk of the rates
+// Scenario:
+// 	- 8 rates, i.e. 7 daily returns with 3 losses
+// 	- confidence of 0.8
+//
+// Expect:
+// 	- volatility is the sample standard deviation of the log returns
+// 	- max drawdown is the fall from 1.12 to 1.11
+// 	- Value-at-Risk is the second largest loss, from 1.15 to 1.14
+func TestRiskMetrics(t *testing.T) {
+	ratesList := ratesListOf(1.10, 1.12, 1.11, 1.13, 1.15, 1.14, 1.16, 1.15)
+
+	risk, err := RiskMetrics(ratesList, 0.8)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, risk.Returns)
+	assert.InDelta(t, 0.014161173020295766, risk.Volatility, 1e-12, "volatility is wrong")
+	assert.InDelta(t, 0.2248016525079602, risk.AnnualisedVolatility, 1e-12, "annualised volatility is wrong")
+	assert.InDelta(t, 0.008928571428571435, risk.MaxDrawdown, 1e-12, "max drawdown is wrong")
+	assert.InDelta(t, 0.008695652173913104, risk.ValueAtRisk, 1e-12, "value at risk is wrong")
+	assert.Equal(t, 0.8, risk.Confidence)
+}
+
+// TestRiskMetricsNoLoss checks there is no drawdown
+// and no Value-at-Risk if the rates only rise
+func TestRiskMetricsNoLoss(t *testing.T) {
+	risk, err := RiskMetrics(ratesListOf(1.10, 1.11, 1.13, 1.14), DefaultVaRConfidence)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, risk.MaxDrawdown)
+	assert.Equal(t, 0.0, risk.ValueAtRisk)
+}
+
+// TestRiskMetricsError checks the rates and
+// confidence are validated
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error is returned
+func TestRiskMetricsError(t *testing.T) {
+	type testParams struct {
+		description string
+		ratesList   model.RatesList
+		confidence  float64
+		expErr      error
+	}
+
+	cases := []testParams{
+		{
+			description: "ErrInsufficientData if too few rates",
+			ratesList:   ratesListOf(1.10, 1.11),
+			confidence:  DefaultVaRConfidence,
+			expErr:      ErrInsufficientData,
+		},
+		{
+			description: "SeriesError if a rate is not positive",
+			ratesList:   ratesListOf(1.10, 0, 1.11),
+			confidence:  DefaultVaRConfidence,
+			expErr:      NewSeriesError("2019-11-02", "EUR rate 0 is not a positive number"),
+		},
+		{
+			description: "error if the confidence is not a number",
+			ratesList:   ratesListOf(1.10, 1.11, 1.12),
+			confidence:  math.NaN(),
+			expErr:      errors.New("confidence must be between 0 and 1, got NaN"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			_, err := RiskMetrics(tt.ratesList, tt.confidence)
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+		})
+	}
+
+	_, err := RiskMetrics(ratesListOf(1.10, 1.11, 1.12), 1)
+	assert.Error(t, err)
+}
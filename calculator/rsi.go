@@ -0,0 +1,108 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// DefaultRSIPeriod is the default number of rate
+	// changes the RSI is smoothed over
+	DefaultRSIPeriod = 14
+
+	// DefaultRSIOverbought is the default RSI above which
+	// the rate is considered high
+	DefaultRSIOverbought = 70.0
+
+	// DefaultRSIOversold is the default RSI below which
+	// the rate is considered low
+	DefaultRSIOversold = 30.0
+)
+
+type rsiEngine struct {
+	period     int
+	overbought float64
+	oversold   float64
+}
+
+// NewRSIEngine initialises the calculator engine that
+// recommends on the Relative Strength Index of the rates
+func NewRSIEngine(period int, overbought, oversold float64) (Engine, error) {
+	if period < 1 {
+		return nil, fmt.Errorf("period must be positive, got %d", period)
+	}
+	if oversold < 0 || overbought > 100 || oversold >= overbought {
+		return nil, fmt.Errorf("thresholds must satisfy 0 <= oversold < overbought <= 100, got oversold %v overbought %v",
+			oversold, overbought)
+	}
+
+	return &rsiEngine{
+		period:     period,
+		overbought: overbought,
+		oversold:   oversold,
+	}, nil
+}
+
+// Recommend:
+// 1) takes a list of rates and orders them by date
+// 2) computes Wilder's RSI of the rates
+// 3) returns 'convert' if the RSI is at or above overbought,
+//    i.e. the rate has risen strongly and 1 currency buys
+//    many euros, returns 'don't convert' if it is at or below
+//    oversold, and 'neutral' otherwise or if the rates are
+//    flat, as their RSI of 50 is no signal whatever the
+//    thresholds are.
+// It returns ErrInsufficientData if there are too few rates
+// for a period of changes.
+func (e *rsiEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, e.period+1)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	rates := values(series)
+	if flat(rates) {
+		return Recommendation{Signal: SignalNeutral}, nil
+	}
+
+	r := rsi(rates, e.period)
+	if r >= e.overbought {
+		return Recommendation{Signal: SignalConvert}, nil
+	} else if r <= e.oversold {
+		return Recommendation{Signal: SignalNoConvert}, nil
+	}
+	return Recommendation{Signal: SignalNeutral}, nil
+}
+
+// rsi computes Wilder's Relative Strength Index of the latest
+// rate. The first average gain and loss are the simple averages
+// of the first period changes, later ones are smoothed with
+// (previous*(period-1) + current) / period.
+// There must be more than period rates.
+func rsi(rates []float64, period int) float64 {
+	var avgGain, avgLoss float64
+	for i := 1; i < len(rates); i++ {
+		gain, loss := 0.0, 0.0
+		if change := rates[i] - rates[i-1]; change > 0 {
+			gain = change
+		} else {
+			loss = -change
+		}
+
+		if i <= period {
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+		} else {
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		}
+	}
+
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
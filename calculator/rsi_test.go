@@ -0,0 +1,126 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// referenceCloses is the reference series Wilder's RSI is
+// commonly illustrated with, e.g. in the StockCharts RSI
+// spreadsheet, with the 14 period RSI in referenceRSI
+var referenceCloses = []float64{
+	44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245,
+	45.8433, 46.0826, 45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028,
+	46.0328, 46.4116, 46.2222, 45.6439, 46.2122, 46.2521, 45.7137, 46.4515,
+	45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672, 43.4205, 42.6628,
+	43.1314,
+}
+
+var referenceRSI = []float64{
+	70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+	54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+}
+
+// TestRSI checks Wilder's RSI matches the reference series
+// Scenario:
+// 	- given the reference closes
+//
+// Expect:
+// 	- RSI of each day from the 15th matches the reference to 2 decimals
+func TestRSI(t *testing.T) {
+	for i, exp := range referenceRSI {
+		rates := referenceCloses[:DefaultRSIPeriod+1+i]
+		assert.InDelta(t, exp, rsi(rates, DefaultRSIPeriod), 0.005, "RSI of day %d is wrong", len(rates))
+	}
+
+	assert.Equal(t, 100.0, rsi([]float64{1, 2, 3}, 2))
+	assert.Equal(t, 50.0, rsi([]float64{1, 1, 1}, 2))
+}
+
+// TestRSIRecommend checks that the right signal
+// recommendation is given based on the RSI
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given
+func TestRSIRecommend(t *testing.T) {
+	type testParams struct {
+		description       string
+		rates             []float64
+		expRecommendation Signal
+		expErr            error
+	}
+
+	cases := []testParams{
+		{
+			description:       "SignalConvert if RSI is overbought, 70.53",
+			rates:             referenceCloses[:15],
+			expRecommendation: SignalConvert,
+		},
+		{
+			description:       "SignalNeutral if RSI is in between, 66.32",
+			rates:             referenceCloses[:16],
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description:       "SignalNoConvert if RSI is oversold, 33.08",
+			rates:             referenceCloses[:32],
+			expRecommendation: SignalNoConvert,
+		},
+		{
+			description: "ErrInsufficientData if too few rates",
+			rates:       referenceCloses[:14],
+			expErr:      ErrInsufficientData,
+		},
+	}
+
+	e, err := NewRSIEngine(DefaultRSIPeriod, DefaultRSIOverbought, 35)
+	assert.NoError(t, err)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			recommendation, err := e.Recommend(ratesListOf(tt.rates...))
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expRecommendation, recommendation.Signal,
+				"recommendation is wrong")
+		})
+	}
+}
+
+// TestNewRSIEngineInvalid checks invalid
+// periods and thresholds are rejected
+func TestNewRSIEngineInvalid(t *testing.T) {
+	_, err := NewRSIEngine(0, DefaultRSIOverbought, DefaultRSIOversold)
+	assert.Error(t, err)
+
+	_, err = NewRSIEngine(DefaultRSIPeriod, 30, 70)
+	assert.Error(t, err)
+
+	_, err = NewRSIEngine(DefaultRSIPeriod, 110, DefaultRSIOversold)
+	assert.Error(t, err)
+}
+
+// TestRSIRecommendFlat checks flat rates are neutral
+// whatever the thresholds are
+// Scenario:
+// 	- the RSI of flat rates is 50
+// 	- the overbought or the oversold threshold is 50
+//
+// Expect:
+// 	- neutral rather than convert or don't convert
+func TestRSIRecommendFlat(t *testing.T) {
+	rates := make([]float64, DefaultRSIPeriod+1)
+	for i := range rates {
+		rates[i] = 1.1
+	}
+
+	for _, thresholds := range [][2]float64{{50, 10}, {90, 50}} {
+		e, err := NewRSIEngine(DefaultRSIPeriod, thresholds[0], thresholds[1])
+		assert.NoError(t, err)
+		recommendation, err := e.Recommend(ratesListOf(rates...))
+		assert.NoError(t, err)
+		assert.Equal(t, SignalNeutral, recommendation.Signal, "thresholds %v", thresholds)
+	}
+}
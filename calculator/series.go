@@ -0,0 +1,159 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	IntervalDaily   Interval = "daily"
+	IntervalWeekly  Interval = "weekly"
+	IntervalMonthly Interval = "monthly"
+)
+
+// Interval is the custom string for the
+// sampling interval of a series
+type Interval string
+
+// ParseInterval validates the interval string,
+// an empty string defaults to daily
+func ParseInterval(s string) (Interval, error) {
+	switch i := Interval(s); i {
+	case "":
+		return IntervalDaily, nil
+	case IntervalDaily, IntervalWeekly, IntervalMonthly:
+		return i, nil
+	default:
+		return "", fmt.Errorf("unknown interval: %s", s)
+	}
+}
+
+// Series returns the rates of the currency ordered
+// by date in ascending order. Dates without a rate
+// for the currency are skipped.
+func Series(ratesList model.RatesList, currency string) []model.RatePoint {
+	var series []model.RatePoint
+	for _, d := range sortedDates(ratesList) {
+		if r, ok := ratesList[d][currency]; ok {
+			series = append(series, model.RatePoint{Date: d, Rate: r})
+		}
+	}
+	return series
+}
+
+// ValidSeries returns the rates of the currency ordered by date,
+// like Series, but errors with a SeriesError if a date is malformed
+// or has no valid rate for the currency
+func ValidSeries(ratesList model.RatesList, currency string) ([]model.RatePoint, error) {
+	return validSeries(ratesList, currency, 0)
+}
+
+// validSeries returns the rates of the currency ordered by date,
+// like Series, but errors with a SeriesError if a date is malformed
+// or has no valid rate for the currency, and with ErrInsufficientData
+// if there are fewer than min rates.
+func validSeries(ratesList model.RatesList, currency string, min int) ([]model.RatePoint, error) {
+	var series []model.RatePoint
+	for _, d := range sortedDates(ratesList) {
+		if _, err := date.Parse(d); err != nil {
+			return nil, NewSeriesError(d, "malformed date")
+		}
+
+		r, ok := ratesList[d][currency]
+		if !ok {
+			return nil, NewSeriesError(d, "missing "+currency+" rate")
+		}
+		if math.IsNaN(r) || math.IsInf(r, 0) || r <= 0 {
+			return nil, NewSeriesError(d, fmt.Sprintf("%s rate %v is not a positive number", currency, r))
+		}
+
+		series = append(series, model.RatePoint{Date: d, Rate: r})
+	}
+
+	if len(series) < min {
+		return nil, ErrInsufficientData
+	}
+	return series, nil
+}
+
+// values returns the rates of the series
+func values(series []model.RatePoint) []float64 {
+	rates := make([]float64, len(series))
+	for i, p := range series {
+		rates[i] = p.Rate
+	}
+	return rates
+}
+
+// flat returns whether the rates are all equal. They have no
+// spread to measure the latest rate against then, their standard
+// deviation is 0 though rounding can make it a tiny positive number.
+func flat(rates []float64) bool {
+	for _, r := range rates {
+		if r != rates[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// Resample keeps the last rate of every period of the interval,
+// e.g. the closing rate of each week for IntervalWeekly.
+// The series must be sorted by date.
+func Resample(series []model.RatePoint, interval Interval) ([]model.RatePoint, error) {
+	if interval == IntervalDaily {
+		return series, nil
+	}
+
+	var resampled []model.RatePoint
+	prevPeriod := ""
+	for _, p := range series {
+		period, err := periodOf(p.Date, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		if period == prevPeriod {
+			resampled[len(resampled)-1] = p
+		} else {
+			resampled = append(resampled, p)
+		}
+		prevPeriod = period
+	}
+	return resampled, nil
+}
+
+// periodOf returns the key of the period the date falls in,
+// i.e. the ISO week or the month
+func periodOf(d string, interval Interval) (string, error) {
+	t, err := date.Parse(d)
+	if err != nil {
+		return "", err
+	}
+
+	switch interval {
+	case IntervalWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	case IntervalMonthly:
+		return t.Format("2006-01"), nil
+	default:
+		return "", errors.New("can't resample to interval " + string(interval))
+	}
+}
+
+// sortedDates returns the keys of RatesList, i.e. date strings,
+// in ascending order
+func sortedDates(ratesList model.RatesList) []string {
+	var dates []string
+	for d := range ratesList {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+	return dates
+}
@@ -0,0 +1,202 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSeries checks the rates of the currency are
+// sorted by date in ascending order
+// Scenario:
+// 	- given a rates list with dates in random order
+// 	- one of the dates has no rate for the currency
+//
+// Expect:
+// 	- rates are sorted with their dates
+// 	- date without the rate is skipped
+func TestSeries(t *testing.T) {
+	ratesList := model.RatesList{
+		"2019-11-21": {
+			EUR: 1.1689343994,
+		},
+		"2019-11-15": {
+			EUR: 1.1674060238,
+		},
+		"2019-11-22": {
+			EUR: 1.163061177,
+		},
+		"2019-11-20": {
+			"GBP": 0.85,
+		},
+	}
+
+	expSeries := []model.RatePoint{
+		{Date: "2019-11-15", Rate: 1.1674060238},
+		{Date: "2019-11-21", Rate: 1.1689343994},
+		{Date: "2019-11-22", Rate: 1.163061177},
+	}
+
+	assert.Equal(t, expSeries, Series(ratesList, EUR), "series don't match")
+}
+
+// TestValidSeries checks that rates which can't be used
+// for a recommendation are reported instead of skipped
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right series or error is returned
+func TestValidSeries(t *testing.T) {
+	type testParams struct {
+		description string
+		ratesList   model.RatesList
+		min         int
+		expSeries   []model.RatePoint
+		expErr      error
+	}
+
+	cases := []testParams{
+		{
+			description: "rates sorted by date",
+			ratesList: model.RatesList{
+				"2019-11-22": {EUR: 1.163061177},
+				"2019-11-21": {EUR: 1.1689343994},
+			},
+			min: 2,
+			expSeries: []model.RatePoint{
+				{Date: "2019-11-21", Rate: 1.1689343994},
+				{Date: "2019-11-22", Rate: 1.163061177},
+			},
+		},
+		{
+			description: "ErrInsufficientData if fewer rates than min",
+			ratesList:   ratesListOf(1.16, 1.17),
+			min:         3,
+			expErr:      ErrInsufficientData,
+		},
+		{
+			description: "ErrInsufficientData if empty",
+			ratesList:   model.RatesList{},
+			min:         1,
+			expErr:      ErrInsufficientData,
+		},
+		{
+			description: "SeriesError if the rate is missing",
+			ratesList: model.RatesList{
+				"2019-11-21": {EUR: 1.1689343994},
+				"2019-11-22": {"GBP": 0.85},
+			},
+			min:    1,
+			expErr: NewSeriesError("2019-11-22", "missing EUR rate"),
+		},
+		{
+			description: "SeriesError if the rate is zero",
+			ratesList: model.RatesList{
+				"2019-11-21": {EUR: 0},
+			},
+			min:    1,
+			expErr: NewSeriesError("2019-11-21", "EUR rate 0 is not a positive number"),
+		},
+		{
+			description: "SeriesError if the date is malformed",
+			ratesList: model.RatesList{
+				"21/11/2019": {EUR: 1.1689343994},
+			},
+			min:    1,
+			expErr: NewSeriesError("21/11/2019", "malformed date"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			series, err := validSeries(tt.ratesList, EUR, tt.min)
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expSeries, series, "series don't match")
+		})
+	}
+}
+
+// TestResample checks the last rate of each period is kept
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right rates are kept
+func TestResample(t *testing.T) {
+	series := []model.RatePoint{
+		{Date: "2019-10-30", Rate: 1.10},
+		{Date: "2019-10-31", Rate: 1.11},
+		{Date: "2019-11-01", Rate: 1.12},
+		{Date: "2019-11-04", Rate: 1.13},
+		{Date: "2019-11-08", Rate: 1.14},
+		{Date: "2019-11-11", Rate: 1.15},
+	}
+
+	type testParams struct {
+		description string
+		interval    Interval
+		expSeries   []model.RatePoint
+	}
+
+	cases := []testParams{
+		{
+			description: "daily keeps every rate",
+			interval:    IntervalDaily,
+			expSeries:   series,
+		},
+		{
+			description: "weekly keeps the last rate of each ISO week",
+			interval:    IntervalWeekly,
+			expSeries: []model.RatePoint{
+				{Date: "2019-11-01", Rate: 1.12},
+				{Date: "2019-11-08", Rate: 1.14},
+				{Date: "2019-11-11", Rate: 1.15},
+			},
+		},
+		{
+			description: "monthly keeps the last rate of each month",
+			interval:    IntervalMonthly,
+			expSeries: []model.RatePoint{
+				{Date: "2019-10-31", Rate: 1.11},
+				{Date: "2019-11-11", Rate: 1.15},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			resampled, err := Resample(series, tt.interval)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expSeries, resampled, "resampled series don't match")
+		})
+	}
+}
+
+// TestParseInterval checks that only known intervals are accepted
+func TestParseInterval(t *testing.T) {
+	interval, err := ParseInterval("")
+	assert.NoError(t, err)
+	assert.Equal(t, IntervalDaily, interval)
+
+	interval, err = ParseInterval("weekly")
+	assert.NoError(t, err)
+	assert.Equal(t, IntervalWeekly, interval)
+
+	_, err = ParseInterval("hourly")
+	assert.Error(t, err)
+}
+
+// ratesListOf builds a RatesList of EUR rates
+// on consecutive days from 2019-11-01
+func ratesListOf(rates ...float64) model.RatesList {
+	start := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	ratesList := model.RatesList{}
+	for i, r := range rates {
+		ratesList[date.Format(start.AddDate(0, 0, i))] = model.Rates{EUR: r}
+	}
+	return ratesList
+}
@@ -0,0 +1,87 @@
+package calculator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// AxisCalendarDays places the rates on the timeline
+	// by the calendar days since the first rate, e.g.
+	// Friday to Monday is 3 days
+	AxisCalendarDays TimeAxis = "calendar-days"
+
+	// AxisBusinessDays places the rates on the timeline
+	// by the weekdays since the first rate, e.g. Friday
+	// to Monday is 1 day, while a holiday still counts
+	AxisBusinessDays TimeAxis = "business-days"
+)
+
+// TimeAxis is the custom string for how the
+// dates of the rates are measured
+type TimeAxis string
+
+// ParseTimeAxis validates the time axis string
+func ParseTimeAxis(s string) (TimeAxis, error) {
+	switch a := TimeAxis(s); a {
+	case AxisCalendarDays, AxisBusinessDays:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown time axis: %s", s)
+	}
+}
+
+// timeline returns the day offset of every rate of the series
+// since the first rate, and the number of gaps, i.e. weekdays
+// without a rate between the first and the last rate. The
+// series must be sorted by date.
+func timeline(series []model.RatePoint, axis TimeAxis) ([]float64, int, error) {
+	offsets := make([]float64, len(series))
+	if len(series) == 0 {
+		return offsets, 0, nil
+	}
+
+	first, err := date.Parse(series[0].Date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	prev := first
+	gaps := 0
+	for i, p := range series {
+		d, err := date.Parse(p.Date)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch axis {
+		case AxisBusinessDays:
+			offsets[i] = float64(weekdaysBetween(first, d))
+		default:
+			offsets[i] = d.Sub(first).Hours() / 24
+		}
+
+		if i > 0 {
+			if missing := weekdaysBetween(prev, d) - 1; missing > 0 {
+				gaps += missing
+			}
+		}
+		prev = d
+	}
+	return offsets, gaps, nil
+}
+
+// weekdaysBetween counts the weekdays after from
+// up to and including to
+func weekdaysBetween(from, to time.Time) int {
+	n := 0
+	for d := from.AddDate(0, 0, 1); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTimeline checks the rates are placed on the time axis
+// by their dates, with weekends and gaps handled explicitly
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right day offsets and number of gaps are returned
+func TestTimeline(t *testing.T) {
+	type testParams struct {
+		description string
+		series      []model.RatePoint
+		axis        TimeAxis
+		expOffsets  []float64
+		expGaps     int
+	}
+
+	// Thursday, Friday, Monday, then Wednesday after
+	// a missing Tuesday, e.g. a holiday
+	irregular := []model.RatePoint{
+		{Date: "2019-12-19", Rate: 1.11},
+		{Date: "2019-12-20", Rate: 1.11},
+		{Date: "2019-12-23", Rate: 1.11},
+		{Date: "2019-12-25", Rate: 1.11},
+	}
+
+	cases := []testParams{
+		{
+			description: "calendar days count weekends and holidays",
+			series:      irregular,
+			axis:        AxisCalendarDays,
+			expOffsets:  []float64{0, 1, 4, 6},
+			expGaps:     1,
+		},
+		{
+			description: "business days skip weekends but count holidays",
+			series:      irregular,
+			axis:        AxisBusinessDays,
+			expOffsets:  []float64{0, 1, 2, 4},
+			expGaps:     1,
+		},
+		{
+			description: "missing week is 5 gaps",
+			series: []model.RatePoint{
+				{Date: "2019-11-01", Rate: 1.11},
+				{Date: "2019-11-11", Rate: 1.11},
+			},
+			axis:       AxisBusinessDays,
+			expOffsets: []float64{0, 6},
+			expGaps:    5,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			offsets, gaps, err := timeline(tt.series, tt.axis)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expOffsets, offsets, "offsets are wrong")
+			assert.Equal(t, tt.expGaps, gaps, "gaps are wrong")
+		})
+	}
+}
+
+// TestTrendOnIrregularSeries checks the trend follows the
+// calendar rather than the order of the rates
+// Scenario:
+// 	- rates rise by 0.01 per step for 4 consecutive days,
+// 	  and by 0.01 again after a 10 day gap
+//
+// Expect:
+// 	- slope per calendar day is lower than per step,
+// 	  i.e. the gap is not treated as a single day
+func TestTrendOnIrregularSeries(t *testing.T) {
+	series := []model.RatePoint{
+		{Date: "2019-11-04", Rate: 1.10},
+		{Date: "2019-11-05", Rate: 1.11},
+		{Date: "2019-11-06", Rate: 1.12},
+		{Date: "2019-11-07", Rate: 1.13},
+		{Date: "2019-11-17", Rate: 1.14},
+	}
+
+	analysis, err := analyse(series, AxisCalendarDays)
+	assert.NoError(t, err)
+	assert.True(t, analysis.Slope > 0 && analysis.Slope < 0.005, "slope %v is wrong", analysis.Slope)
+	assert.Equal(t, 5, analysis.Gaps, "gaps are wrong")
+}
+
+// TestParseTimeAxis checks that only known axes are accepted
+func TestParseTimeAxis(t *testing.T) {
+	axis, err := ParseTimeAxis("business-days")
+	assert.NoError(t, err)
+	assert.Equal(t, AxisBusinessDays, axis)
+
+	_, err = ParseTimeAxis("hours")
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package calculator
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/model"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	// DefaultZScoreThreshold is the default number of standard
+	// deviations the latest rate has to be away from the mean
+	DefaultZScoreThreshold = 1.0
+)
+
+type zScoreEngine struct {
+	threshold float64
+}
+
+// NewZScoreEngine initialises the calculator engine that
+// recommends on how unusual the latest rate is compared
+// to the mean of the rates
+func NewZScoreEngine(threshold float64) (Engine, error) {
+	if threshold <= 0 {
+		return nil, fmt.Errorf("threshold must be positive, got %v", threshold)
+	}
+
+	return &zScoreEngine{
+		threshold: threshold,
+	}, nil
+}
+
+// Recommend:
+// 1) takes a list of rates and orders them by date
+// 2) computes the mean and standard deviation of the rates
+// 3) computes the z-score of the latest rate
+// 4) returns 'convert' if the latest rate is at least threshold
+//    standard deviations above the mean, i.e. 1 currency buys
+//    unusually many euros, returns 'don't convert' if it is at
+//    least threshold standard deviations below, and 'neutral'
+//    otherwise.
+// It returns ErrInsufficientData if there are fewer than
+// MinDataPoints rates.
+func (e *zScoreEngine) Recommend(ratesList model.RatesList) (Recommendation, error) {
+	series, err := validSeries(ratesList, EUR, MinDataPoints)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	rates := values(series)
+
+	z := zScore(rates)
+	if z >= e.threshold {
+		return Recommendation{Signal: SignalConvert}, nil
+	} else if z <= -e.threshold {
+		return Recommendation{Signal: SignalNoConvert}, nil
+	}
+	return Recommendation{Signal: SignalNeutral}, nil
+}
+
+// zScore computes the number of standard deviations the
+// latest rate is away from the mean of the rates.
+// It is 0 if the rates are flat.
+func zScore(rates []float64) float64 {
+	if flat(rates) {
+		return 0
+	}
+	mean, std := stat.MeanStdDev(rates, nil)
+	return (rates[len(rates)-1] - mean) / std
+}
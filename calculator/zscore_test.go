@@ -0,0 +1,76 @@
+package calculator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestZScore checks the z-score of the latest rate
+// is computed with the sample standard deviation
+func TestZScore(t *testing.T) {
+	// mean 3, sample std sqrt(2.5)
+	rates := []float64{1, 2, 3, 4, 5}
+	assert.InDelta(t, 1.2649110640673518, zScore(rates), 1e-12)
+
+	assert.Equal(t, 0.0, zScore([]float64{1, 1, 1}))
+}
+
+// TestZScoreRecommend checks that the right signal
+// recommendation is given based on the z-score
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right recommendation is given
+func TestZScoreRecommend(t *testing.T) {
+	type testParams struct {
+		description       string
+		rates             []float64
+		expRecommendation Signal
+		expErr            error
+	}
+
+	cases := []testParams{
+		{
+			description:       "SignalConvert if latest rate is unusually high",
+			rates:             []float64{1.10, 1.11, 1.10, 1.11, 1.15},
+			expRecommendation: SignalConvert,
+		},
+		{
+			description:       "SignalNoConvert if latest rate is unusually low",
+			rates:             []float64{1.10, 1.11, 1.10, 1.11, 1.05},
+			expRecommendation: SignalNoConvert,
+		},
+		{
+			description:       "SignalNeutral if latest rate is close to the mean",
+			rates:             []float64{1.10, 1.12, 1.10, 1.12, 1.11},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description:       "SignalNeutral if the rates are flat",
+			rates:             []float64{1.1, 1.1, 1.1, 1.1, 1.1},
+			expRecommendation: SignalNeutral,
+		},
+		{
+			description: "ErrInsufficientData if too few rates",
+			rates:       []float64{1.10, 1.15},
+			expErr:      ErrInsufficientData,
+		},
+	}
+
+	e, err := NewZScoreEngine(DefaultZScoreThreshold)
+	assert.NoError(t, err)
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			recommendation, err := e.Recommend(ratesListOf(tt.rates...))
+			assert.Equal(t, tt.expErr, err, "error is wrong")
+			assert.Equal(t, tt.expRecommendation, recommendation.Signal,
+				"recommendation is wrong")
+		})
+	}
+
+	_, err = NewZScoreEngine(0)
+	assert.Error(t, err)
+}
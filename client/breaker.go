@@ -0,0 +1,190 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// DefaultBreakerFailures is the default number of consecutive
+	// failed requests after which the circuit breaker opens
+	DefaultBreakerFailures = 5
+
+	// DefaultBreakerCooldown is the default time the circuit
+	// breaker stays open before letting a request through
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// BreakerState is the state of the circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets the requests through
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen fails the requests without calling the api
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen lets a single request through after the
+	// cooldown, closing the breaker if it succeeds
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// ErrBreakerOpen is returned when the circuit breaker
+// is open and the api is not called
+var ErrBreakerOpen = errors.New("circuit breaker open")
+
+// BreakerSettings holds when the circuit breaker opens
+type BreakerSettings struct {
+	// Failures is the number of consecutive failed requests
+	// after which the breaker opens, 0 disables the breaker
+	Failures int
+
+	// Cooldown is the time the breaker stays open
+	Cooldown time.Duration
+}
+
+// DefaultBreakerSettings returns the Settings the circuit
+// breaker runs with when the operator sets nothing
+func DefaultBreakerSettings() BreakerSettings {
+	return BreakerSettings{
+		Failures: DefaultBreakerFailures,
+		Cooldown: DefaultBreakerCooldown,
+	}
+}
+
+// Validate checks the BreakerSettings are consistent
+func (s BreakerSettings) Validate() error {
+	if s.Failures < 0 {
+		return fmt.Errorf("breaker failures must not be negative, got %d", s.Failures)
+	}
+	if s.Cooldown <= 0 {
+		return fmt.Errorf("breaker cooldown must be positive, got %v", s.Cooldown)
+	}
+	return nil
+}
+
+// BreakerForex is a Forex client failing fast with ErrBreakerOpen
+// once the api failed a number of times in a row, so an unavailable
+// api is not called on every request. Responses with a 4XX status
+// other than 429 are the caller's fault and do not count as failures.
+type BreakerForex struct {
+	fx       Forex
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewBreakerForex initialises a closed circuit breaker calling fx
+func NewBreakerForex(fx Forex, s BreakerSettings) *BreakerForex {
+	return &BreakerForex{
+		settings: s,
+		now:      time.Now,
+		fx:       fx,
+		state:    BreakerClosed,
+	}
+}
+
+// State returns the state of the breaker
+func (b *BreakerForex) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// GetLatestRate gets the latest rate from `currency`
+// to EUR unless the breaker is open
+func (b *BreakerForex) GetLatestRate(currency string) (*model.LatestRate, error) {
+	fx, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	rate, err := fx.GetLatestRate(currency)
+	b.done(err)
+	return rate, err
+}
+
+// GetHistoricalRate gets the rate from `currency` to EUR
+// published on the date unless the breaker is open
+func (b *BreakerForex) GetHistoricalRate(currency string, date string) (*model.LatestRate, error) {
+	fx, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	rate, err := fx.GetHistoricalRate(currency, date)
+	b.done(err)
+	return rate, err
+}
+
+// GetHistoricalRates gets the historical rates from `currency`
+// to EUR of the period unless the breaker is open
+func (b *BreakerForex) GetHistoricalRates(currency string, startDate string, endDate string) (*model.HistoricalRates, error) {
+	fx, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	rates, err := fx.GetHistoricalRates(currency, startDate, endDate)
+	b.done(err)
+	return rates, err
+}
+
+// allow returns the client if the request can be sent, once the
+// cooldown is over a single request is let through half-open
+func (b *BreakerForex) allow() (Forex, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.settings.Cooldown {
+			return nil, ErrBreakerOpen
+		}
+		b.state = BreakerHalfOpen
+	case BreakerHalfOpen:
+		return nil, ErrBreakerOpen
+	}
+	return b.fx, nil
+}
+
+// done records the outcome of a request, a failure opens the breaker
+// if it is half-open or it is the last of the consecutive failures
+func (b *BreakerForex) done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isFailure(err) {
+		b.state, b.failures = BreakerClosed, 0
+		return
+	}
+
+	b.failures++
+	if b.settings.Failures == 0 {
+		return
+	}
+	if b.state == BreakerHalfOpen || b.failures >= b.settings.Failures {
+		b.state, b.openedAt = BreakerOpen, b.now()
+	}
+}
+
+// isFailure returns whether the error is a failure of the api
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := err.(*HTTPClientError); ok {
+		code := e.StatusCode()
+		if code >= 400 && code < 500 && code != http.StatusTooManyRequests {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	clientmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBreakerForex checks the breaker opens after the
+// consecutive failures and closes once the api recovers
+// Scenario:
+// 	- the breaker opens after 2 failures and cools down for a minute
+// 	- the api fails twice, then succeeds
+//
+// Expect:
+// 	- the breaker opens after the second failure and fails fast
+// 	- after the cooldown a single request is let through and closes the breaker
+func TestBreakerForex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := clientmock.NewMockForex(ctrl)
+
+	now := time.Date(2019, 11, 22, 12, 0, 0, 0, time.UTC)
+	b := NewBreakerForex(mockFX, BreakerSettings{Failures: 2, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	latest := &model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}
+	gomock.InOrder(
+		mockFX.EXPECT().GetLatestRate("USD").Return(nil, errors.New("timeout")).Times(2),
+		mockFX.EXPECT().GetLatestRate("USD").Return(latest, nil),
+	)
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, BreakerClosed, b.State())
+		_, err := b.GetLatestRate("USD")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, BreakerOpen, b.State())
+	_, err := b.GetHistoricalRates("USD", "2019-11-15", "2019-11-22")
+	assert.Equal(t, ErrBreakerOpen, err)
+
+	now = now.Add(time.Minute)
+	rate, err := b.GetLatestRate("USD")
+	assert.NoError(t, err)
+	assert.Equal(t, latest, rate)
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+// TestBreakerForexHalfOpen checks a failure half-open opens
+// the breaker again and client errors are not failures
+func TestBreakerForexHalfOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := clientmock.NewMockForex(ctrl)
+
+	now := time.Date(2019, 11, 22, 12, 0, 0, 0, time.UTC)
+	b := NewBreakerForex(mockFX, BreakerSettings{Failures: 1, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	notFound := NewHTTPClientError("http://localhost", "GetHistoricalRate", &StatusError{Code: 400, Status: "400 Bad Request"})
+	unavailable := NewHTTPClientError("http://localhost", "GetHistoricalRate", &StatusError{Code: 503, Status: "503 Service Unavailable"})
+	gomock.InOrder(
+		mockFX.EXPECT().GetHistoricalRate("XXX", "2019-11-21").Return(nil, notFound),
+		mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").Return(nil, unavailable).Times(2),
+	)
+
+	_, err := b.GetHistoricalRate("XXX", "2019-11-21")
+	assert.Equal(t, notFound, err)
+	assert.Equal(t, BreakerClosed, b.State())
+
+	_, err = b.GetHistoricalRate("USD", "2019-11-21")
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(time.Minute)
+	_, err = b.GetHistoricalRate("USD", "2019-11-21")
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, BreakerOpen, b.State())
+	_, err = b.GetHistoricalRate("USD", "2019-11-21")
+	assert.Equal(t, ErrBreakerOpen, err)
+
+	assert.Error(t, BreakerSettings{Failures: -1, Cooldown: time.Minute}.Validate())
+	assert.Error(t, BreakerSettings{Cooldown: 0}.Validate())
+	assert.NoError(t, DefaultBreakerSettings().Validate())
+}
+
+// TestBreakerForexFailures checks which errors count as failures of
+// the api. A 4XX response other than 429 is the fault of the request,
+// e.g. an unknown currency or date, and the api answered it, so it
+// must not open the breaker for every other caller. A 429 means the
+// quota is used up, and errors without a response, 5XX responses and
+// timeouts mean the api is unavailable: backing off helps in both cases.
+// Scenario:
+// 	- a breaker opening after a single failure calls an api failing
+// 	  with the error of the description
+//
+// Expect:
+// 	- the breaker opens only if the error is a failure
+func TestBreakerForexFailures(t *testing.T) {
+	status := func(code int) error {
+		return NewHTTPClientError("http://localhost", "GetLatestRate",
+			&StatusError{Code: code, Status: http.StatusText(code)})
+	}
+
+	cases := []struct {
+		description string
+		err         error
+		failure     bool
+	}{
+		{description: "no error", err: nil, failure: false},
+		{description: "400 bad request", err: status(http.StatusBadRequest), failure: false},
+		{description: "404 not found", err: status(http.StatusNotFound), failure: false},
+		{description: "429 too many requests", err: status(http.StatusTooManyRequests), failure: true},
+		{description: "500 internal server error", err: status(http.StatusInternalServerError), failure: true},
+		{description: "503 service unavailable", err: status(http.StatusServiceUnavailable), failure: true},
+		{
+			description: "timeout without a response",
+			err:         NewHTTPClientError("http://localhost", "GetLatestRate", errors.New("timeout")),
+			failure:     true,
+		},
+		{description: "connection refused", err: errors.New("connection refused"), failure: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockFX := clientmock.NewMockForex(ctrl)
+			mockFX.EXPECT().GetLatestRate("USD").Return(nil, tt.err)
+
+			b := NewBreakerForex(mockFX, BreakerSettings{Failures: 1, Cooldown: time.Minute})
+			_, err := b.GetLatestRate("USD")
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.failure, isFailure(err))
+			if tt.failure {
+				assert.Equal(t, BreakerOpen, b.State())
+			} else {
+				assert.Equal(t, BreakerClosed, b.State())
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	// DefaultLatestTTL is the default time
+	// the latest rates are cached
+	DefaultLatestTTL = time.Minute
+
+	// DefaultHistoryTTL is the default time
+	// the historical rates are cached
+	DefaultHistoryTTL = time.Hour
+
+	// maxCacheEntries is the max number of cached entries,
+	// the least recently used entry is evicted beyond it
+	maxCacheEntries = 1024
+)
+
+// CacheTTLs is how long the responses of the api are
+// cached, 0 disables caching of the responses
+type CacheTTLs struct {
+	// Latest is the TTL of the latest rates
+	Latest time.Duration
+
+	// History is the TTL of the rates of a
+	// date and the rates of a period
+	History time.Duration
+}
+
+// DefaultCacheTTLs returns the TTLs the cache
+// runs with when the operator sets nothing
+func DefaultCacheTTLs() CacheTTLs {
+	return CacheTTLs{
+		Latest:  DefaultLatestTTL,
+		History: DefaultHistoryTTL,
+	}
+}
+
+// Validate checks the TTLs are not negative
+func (t CacheTTLs) Validate() error {
+	if t.Latest < 0 || t.History < 0 {
+		return fmt.Errorf("cache TTLs must not be negative, got %v and %v", t.Latest, t.History)
+	}
+	return nil
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// CachedForex is a Forex client caching the responses of
+// another Forex client for their TTL. It is immutable, With
+// derives a CachedForex of another client or TTLs to replace
+// it while requests in flight finish with the one they
+// started with.
+type CachedForex struct {
+	fx      Forex
+	ttls    CacheTTLs
+	now     func() time.Time
+	metrics *Metrics
+	cache   *lru
+}
+
+// lru holds up to maxEntries responses, evicting
+// the least recently used one beyond it
+type lru struct {
+	maxEntries int
+
+	mu sync.Mutex
+	// entries indexes the elements of recent, which
+	// is ordered from the most recently used entry
+	entries map[string]*list.Element
+	recent  *list.List
+}
+
+// NewCachedForex initialises a Forex client caching the responses
+// of fx for their TTL, errors are not cached. The hits and misses
+// are recorded in m, a nil m records nothing.
+func NewCachedForex(fx Forex, ttls CacheTTLs, m *Metrics) *CachedForex {
+	return &CachedForex{
+		fx:      fx,
+		ttls:    ttls,
+		now:     time.Now,
+		metrics: m,
+		cache:   newLRU(maxCacheEntries),
+	}
+}
+
+// newLRU initialises an empty lru
+func newLRU(maxEntries int) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		recent:     list.New(),
+	}
+}
+
+// With returns a CachedForex caching the responses of fx for
+// the TTLs. It shares the cached responses if fx is the client
+// of c, otherwise it starts empty as the responses of another
+// client, e.g. of another provider, must not be served. The
+// cached responses expire at the time set when they were cached.
+func (c *CachedForex) With(fx Forex, ttls CacheTTLs) *CachedForex {
+	next := *c
+	next.fx, next.ttls = fx, ttls
+	if fx != c.fx {
+		next.cache = newLRU(c.cache.maxEntries)
+	}
+	return &next
+}
+
+// GetLatestRate gets the cached latest rate from `currency`
+// to EUR, or gets it from the api if it is not cached
+func (c *CachedForex) GetLatestRate(currency string) (*model.LatestRate, error) {
+	v, err := c.get(PathLatest, c.ttls.Latest, func() (interface{}, error) {
+		return c.fx.GetLatestRate(currency)
+	}, PathLatest, currency)
+	rate, _ := v.(*model.LatestRate)
+	return rate, err
+}
+
+// GetHistoricalRate gets the cached rate from `currency` to EUR
+// published on the date, or gets it from the api if it is not cached
+func (c *CachedForex) GetHistoricalRate(currency string, date string) (*model.LatestRate, error) {
+	v, err := c.get(EndpointHistorical, c.ttls.History, func() (interface{}, error) {
+		return c.fx.GetHistoricalRate(currency, date)
+	}, date, currency)
+	rate, _ := v.(*model.LatestRate)
+	return rate, err
+}
+
+// GetHistoricalRates gets the cached historical rates from `currency` to
+// EUR of the period, or gets them from the api if they are not cached
+func (c *CachedForex) GetHistoricalRates(currency string, startDate string, endDate string) (*model.HistoricalRates, error) {
+	v, err := c.get(PathHistory, c.ttls.History, func() (interface{}, error) {
+		return c.fx.GetHistoricalRates(currency, startDate, endDate)
+	}, PathHistory, currency, startDate, endDate)
+	rates, _ := v.(*model.HistoricalRates)
+	return rates, err
+}
+
+// get returns the value of the endpoint cached under the key parts if
+// it has not expired, otherwise it loads the value and caches it for the ttl
+func (c *CachedForex) get(endpoint string, ttl time.Duration, load func() (interface{}, error), parts ...string) (interface{}, error) {
+	if ttl == 0 {
+		c.metrics.cached(endpoint, false)
+		return load()
+	}
+
+	key := strings.Join(parts, "/")
+	now := c.now()
+	if v, ok := c.cache.lookup(key, now); ok {
+		c.metrics.cached(endpoint, true)
+		return v, nil
+	}
+	c.metrics.cached(endpoint, false)
+
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	c.cache.store(key, v, now.Add(ttl))
+	return v, nil
+}
+
+// lookup returns the value cached under the key if
+// it has not expired and marks it as recently used
+func (l *lru) lookup(key string, now time.Time) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !now.Before(e.expires) {
+		return nil, false
+	}
+	l.recent.MoveToFront(el)
+	return e.value, true
+}
+
+// store caches the value under the key until it expires,
+// evicting the least recently used entries beyond maxEntries
+func (l *lru) store(key string, v interface{}, expires time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.entries[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expires = v, expires
+		l.recent.MoveToFront(el)
+		return
+	}
+
+	l.entries[key] = l.recent.PushFront(&entry{key: key, value: v, expires: expires})
+	for l.recent.Len() > l.maxEntries {
+		oldest := l.recent.Back()
+		l.recent.Remove(oldest)
+		delete(l.entries, oldest.Value.(*entry).key)
+	}
+}
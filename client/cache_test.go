@@ -0,0 +1,169 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	clientmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCachedForex checks the responses are
+// cached until their TTL expires
+// Scenario:
+// 	- latest rates are cached for a minute, historical rates for an hour
+// 	- every rate is requested twice, then again after a minute
+//
+// Expect:
+// 	- the api is called once per rate before the minute
+// 	- the latest rate is requested from the api again after the minute
+func TestCachedForex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := clientmock.NewMockForex(ctrl)
+
+	now := time.Date(2019, 11, 22, 12, 0, 0, 0, time.UTC)
+	fx := NewCachedForex(mockFX, DefaultCacheTTLs(), nil)
+	fx.now = func() time.Time { return now }
+
+	latest := &model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}
+	historical := &model.LatestRate{Rates: model.Rates{"EUR": 0.8}, Base: "USD", Date: "2019-11-21"}
+	history := &model.HistoricalRates{Base: "USD"}
+	mockFX.EXPECT().GetLatestRate("USD").Return(latest, nil).Times(2)
+	mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").Return(historical, nil)
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-15", "2019-11-22").Return(history, nil)
+
+	for i := 0; i < 2; i++ {
+		rate, err := fx.GetLatestRate("USD")
+		assert.NoError(t, err)
+		assert.Equal(t, latest, rate)
+
+		rate, err = fx.GetHistoricalRate("USD", "2019-11-21")
+		assert.NoError(t, err)
+		assert.Equal(t, historical, rate)
+
+		rates, err := fx.GetHistoricalRates("USD", "2019-11-15", "2019-11-22")
+		assert.NoError(t, err)
+		assert.Equal(t, history, rates)
+	}
+
+	now = now.Add(DefaultLatestTTL)
+	_, err := fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+	_, err = fx.GetHistoricalRate("USD", "2019-11-21")
+	assert.NoError(t, err)
+}
+
+// TestCachedForexErrorsAndDisabled checks errors are not
+// cached and a TTL of 0 disables caching
+func TestCachedForexErrorsAndDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := clientmock.NewMockForex(ctrl)
+
+	fx := NewCachedForex(mockFX, CacheTTLs{Latest: DefaultLatestTTL}, nil)
+	latest := &model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}
+	gomock.InOrder(
+		mockFX.EXPECT().GetLatestRate("USD").Return(nil, errors.New("error getting latest rate")),
+		mockFX.EXPECT().GetLatestRate("USD").Return(latest, nil),
+	)
+	mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").Return(latest, nil).Times(2)
+
+	_, err := fx.GetLatestRate("USD")
+	assert.Error(t, err)
+	rate, err := fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+	assert.Equal(t, latest, rate)
+
+	for i := 0; i < 2; i++ {
+		_, err = fx.GetHistoricalRate("USD", "2019-11-21")
+		assert.NoError(t, err)
+	}
+
+	assert.Error(t, CacheTTLs{Latest: -time.Second}.Validate())
+	assert.NoError(t, DefaultCacheTTLs().Validate())
+}
+
+// TestCachedForexWith checks the client and TTLs can be
+// replaced, keeping the cached responses of the same client
+// Scenario:
+// 	- the latest and historical rates are cached
+// 	- the TTL of the latest rates is disabled for the same client
+// 	- then the client is replaced
+//
+// Expect:
+// 	- the client is called for the latest rate again while the
+// 	  historical rate cached before is still served from the cache
+// 	- the new client is called for the historical rate as the
+// 	  responses of the old client are flushed
+// 	- the CachedForex it was derived from is unchanged
+func TestCachedForexWith(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oldFX := clientmock.NewMockForex(ctrl)
+	newFX := clientmock.NewMockForex(ctrl)
+
+	fx := NewCachedForex(oldFX, DefaultCacheTTLs(), nil)
+	latest := &model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}
+	historical := &model.LatestRate{Rates: model.Rates{"EUR": 0.8}, Base: "USD", Date: "2019-11-21"}
+	oldFX.EXPECT().GetLatestRate("USD").Return(latest, nil).Times(3)
+	oldFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").Return(historical, nil)
+	newFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").Return(historical, nil)
+
+	_, err := fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+	_, err = fx.GetHistoricalRate("USD", "2019-11-21")
+	assert.NoError(t, err)
+
+	uncached := fx.With(oldFX, CacheTTLs{History: DefaultHistoryTTL})
+	for i := 0; i < 2; i++ {
+		_, err = uncached.GetLatestRate("USD")
+		assert.NoError(t, err)
+	}
+	rate, err := uncached.GetHistoricalRate("USD", "2019-11-21")
+	assert.NoError(t, err)
+	assert.Equal(t, historical, rate)
+
+	replaced := uncached.With(newFX, DefaultCacheTTLs())
+	rate, err = replaced.GetHistoricalRate("USD", "2019-11-21")
+	assert.NoError(t, err)
+	assert.Equal(t, historical, rate)
+
+	_, err = fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+}
+
+// TestCachedForexEviction checks the least recently
+// used response is evicted beyond the max entries
+// Scenario:
+// 	- the cache holds 2 responses
+// 	- the USD, GBP and CHF rates are requested, USD again
+// 	  before CHF
+//
+// Expect:
+// 	- the GBP rate, the least recently used, is evicted
+// 	  and requested from the api again
+// 	- the USD and CHF rates are served from the cache
+func TestCachedForexEviction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := clientmock.NewMockForex(ctrl)
+
+	fx := NewCachedForex(mockFX, DefaultCacheTTLs(), nil)
+	fx.cache = newLRU(2)
+
+	latest := &model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Date: "2019-11-22"}
+	mockFX.EXPECT().GetLatestRate("USD").Return(latest, nil)
+	mockFX.EXPECT().GetLatestRate("GBP").Return(latest, nil).Times(2)
+	mockFX.EXPECT().GetLatestRate("CHF").Return(latest, nil)
+
+	for _, currency := range []string{"USD", "GBP", "USD", "CHF", "USD", "CHF", "GBP"} {
+		_, err := fx.GetLatestRate(currency)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, fx.cache.recent.Len())
+	assert.Len(t, fx.cache.entries, 2)
+}
@@ -25,3 +25,12 @@ func NewHTTPClientError(url, msg string, err error) error {
 
 	return &HTTPClientError{url, msg, err}
 }
+
+// StatusCode returns the status code of the
+// response, 0 if the request got no response
+func (e *HTTPClientError) StatusCode() int {
+	if statusErr, ok := e.err.(*StatusError); ok {
+		return statusErr.Code
+	}
+	return 0
+}
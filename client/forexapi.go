@@ -10,24 +10,27 @@ import (
 // calling the https://exchangeratesapi.io/ api.
 type Forex interface {
 	GetLatestRate(currency string) (*model.LatestRate, error)
+	GetHistoricalRate(currency string, date string) (*model.LatestRate, error)
 	GetHistoricalRates(currency string, startDate string, endDate string) (*model.HistoricalRates, error)
 }
 
 type forex struct {
 	httpClient HTTPClient
+	baseURL    string
 }
 
 // NewForex initialises a Forex client
-// with a httpClient
-func NewForex(c HTTPClient) Forex {
+// with a httpClient calling the api at baseURL
+func NewForex(c HTTPClient, baseURL string) Forex {
 	return &forex{
 		httpClient: c,
+		baseURL:    baseURL,
 	}
 }
 
 // GetLatestRate gets latest rate from `currency` to EUR
 func (e *forex) GetLatestRate(currency string) (*model.LatestRate, error) {
-	url, err := buildLatestRateURL(currency)
+	url, err := buildLatestRateURL(e.baseURL, currency)
 	if err != nil {
 		return nil, err
 	}
@@ -47,10 +50,33 @@ func (e *forex) GetLatestRate(currency string) (*model.LatestRate, error) {
 	return results, nil
 }
 
+// GetHistoricalRate gets the rate from `currency` to EUR
+// as it was published on the given date
+func (e *forex) GetHistoricalRate(currency string, date string) (*model.LatestRate, error) {
+	url, err := buildHistoricalRateURL(e.baseURL, currency, date)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &model.LatestRate{}
+	resp, err := e.httpClient.GET(url, rate)
+	if err != nil {
+		return nil, NewHTTPClientError(url, "GetHistoricalRate", err)
+	}
+
+	results, ok := resp.Result().(*model.LatestRate)
+	if !ok {
+		return nil, NewHTTPClientError(url, "GetHistoricalRate",
+			errors.New("type assertion error"))
+	}
+
+	return results, nil
+}
+
 // GetHistoricalRates get historical rates from `currency` to EUR
 // with the period from the startDate to the endDate
 func (e *forex) GetHistoricalRates(currency string, startDate string, endDate string) (*model.HistoricalRates, error) {
-	url, err := buildHistoricalRatesURL(currency, startDate, endDate)
+	url, err := buildHistoricalRatesURL(e.baseURL, currency, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
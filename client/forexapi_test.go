@@ -101,6 +101,61 @@ func TestGetLatestRateTypeAssertion(t *testing.T) {
 	assert.Nil(t, latestRate)
 }
 
+// TestGetHistoricalRateHappyCase tests that the rate of a past date
+// is returned when given a currency and a date
+// Scenario:
+// 	- the httpClient is mocked to return a LatestRate object
+// 	- and no error is returned
+//
+// Expect:
+// 	- asserts that no error is returned by GetHistoricalRate
+// 	- asserts that the right LatestRate result is returned
+func TestGetHistoricalRateHappyCase(t *testing.T) {
+	httpClient, forex, ctrl := setupTestForex(t)
+	defer ctrl.Finish()
+
+	mockRate := &model.LatestRate{
+		Rates: model.Rates{
+			"EUR": 1.1689343994,
+		},
+		Base: "GBP",
+		Date: "2019-11-21",
+	}
+	mockHTTPClientResp := &resty.Response{
+		Request: &resty.Request{
+			Result: mockRate,
+		},
+	}
+	httpClient.EXPECT().GET("https://api.exchangeratesapi.io/2019-11-21?base=GBP&symbols=EUR", gomock.Any()).
+		Return(mockHTTPClientResp, nil)
+
+	rate, err := forex.GetHistoricalRate("GBP", "2019-11-21")
+	assert.NoError(t, err)
+	assert.Equal(t, mockRate, rate, "result does not match")
+}
+
+// TestGetHistoricalRateHTTPClientError tests that an error is returned
+// by the method when httpClient has error
+// Scenario:
+// 	- error returned by the httpClient
+//
+// Expect:
+// 	- right HTTPClientError is returned by the method GetHistoricalRate
+//  - result is nil
+func TestGetHistoricalRateHTTPClientError(t *testing.T) {
+	httpClient, forex, ctrl := setupTestForex(t)
+	defer ctrl.Finish()
+
+	httpClient.EXPECT().GET(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("connection closed"))
+	rate, err := forex.GetHistoricalRate("GBP", "2019-11-21")
+
+	expectedErrString := "https://api.exchangeratesapi.io/2019-11-21?base=GBP&symbols=EUR: GetHistoricalRate: connection closed"
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), expectedErrString)
+	assert.Nil(t, rate)
+}
+
 // TestGetHistoricalRatesHappyCase tests that historal rates
 // are returned when given the currency, startDate and endDate
 // Scenario:
@@ -200,6 +255,6 @@ func TestGetHistoricalRatesTypeAssertion(t *testing.T) {
 func setupTestForex(t *testing.T) (*clientmock.MockHTTPClient, Forex, *gomock.Controller) {
 	ctrl := gomock.NewController(t)
 	httpClient := mock.NewMockHTTPClient(ctrl)
-	forex := NewForex(httpClient)
+	forex := NewForex(httpClient, BaseEndpoint)
 	return httpClient, forex, ctrl
 }
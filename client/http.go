@@ -3,12 +3,14 @@ package client
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
 const (
+	// BaseEndpoint is the default base URL of the provider
 	BaseEndpoint   = "https://api.exchangeratesapi.io"
 	PathLatest     = "latest"
 	PathHistory    = "history"
@@ -19,23 +21,68 @@ const (
 	SymbolEuro     = "EUR"
 )
 
-var (
+const (
+	// DefaultRetryCount is the default number of retries
+	// a resty client will perform after a failed request.
+	DefaultRetryCount = 3
+
+	// DefaultRetryWaitTime is the default wait time
+	// the client will wait before retrying
+	DefaultRetryWaitTime = 500 * time.Millisecond
+
+	// DefaultRetryMaxWaitTime is the default max time the
+	// client will sleep before retrying a failed request
+	DefaultRetryMaxWaitTime = 1 * time.Second
+
+	// DefaultTimeout is the default time a resty client
+	// will wait before raising a timeout error.
+	DefaultTimeout = 500 * time.Millisecond
+)
+
+// Settings holds the retries and timeouts of the HTTPClient
+type Settings struct {
 	// RetryCount specifies the number of retries a resty
 	// client will perform after a failed request.
-	RetryCount = 3
+	RetryCount int
 
 	// RetryWaitTime specifies the wait time the client
 	// will wait before retrying
-	RetryWaitTime = 500 * time.Millisecond
+	RetryWaitTime time.Duration
 
 	// RetryMaxWaitTime specifies the max time the client
 	// will sleep before retrying a failed request
-	RetryMaxWaitTime = 1 * time.Second
+	RetryMaxWaitTime time.Duration
 
 	// Timeout specifies the time a resty client will
 	// wait before raising a timeout error.
-	Timeout = 500 * time.Millisecond
-)
+	Timeout time.Duration
+}
+
+// DefaultSettings returns the Settings the
+// client runs with when the operator sets nothing
+func DefaultSettings() Settings {
+	return Settings{
+		RetryCount:       DefaultRetryCount,
+		RetryWaitTime:    DefaultRetryWaitTime,
+		RetryMaxWaitTime: DefaultRetryMaxWaitTime,
+		Timeout:          DefaultTimeout,
+	}
+}
+
+// Validate checks the Settings are consistent
+func (s Settings) Validate() error {
+	if s.RetryCount < 0 {
+		return fmt.Errorf("retry count must not be negative, got %d", s.RetryCount)
+	}
+	if s.RetryWaitTime < 0 || s.RetryMaxWaitTime < s.RetryWaitTime {
+		return fmt.Errorf("retry wait times must satisfy 0 <= wait <= max wait, got %v and %v",
+			s.RetryWaitTime, s.RetryMaxWaitTime)
+	}
+	if s.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %v", s.Timeout)
+	}
+	return nil
+}
 
 // retryCondFunc is called by resty always and not only on client
 // errors. Therefore we need to prevent retrying url errors.
@@ -60,30 +107,54 @@ type HTTPClient interface {
 
 type httpClient struct {
 	*resty.Client
+	metrics *Metrics
 }
 
 // NewHTTPClient returns an instance of resty client
 // which implements the HTTPClient interface.
-// It also sets some configurations for the client.
-func NewHTTPClient() HTTPClient {
+// It also sets the retries and timeouts of the client.
+func NewHTTPClient(s Settings) HTTPClient {
+	return NewInstrumentedHTTPClient(s, nil)
+}
+
+// NewInstrumentedHTTPClient returns an HTTPClient like
+// NewHTTPClient which records its calls, their latency,
+// errors and retries in m, a nil m records nothing
+func NewInstrumentedHTTPClient(s Settings, m *Metrics) HTTPClient {
 	c := resty.New()
-	c.SetRetryCount(RetryCount)
-	c.SetRetryWaitTime(RetryWaitTime)
-	c.SetRetryMaxWaitTime(RetryMaxWaitTime)
-	c.SetTimeout(Timeout)
+	c.SetRetryCount(s.RetryCount)
+	c.SetRetryWaitTime(s.RetryWaitTime)
+	c.SetRetryMaxWaitTime(s.RetryMaxWaitTime)
+	c.SetTimeout(s.Timeout)
 	c.AddRetryCondition(retryCondFunc)
+	c.OnBeforeRequest(countAttempt)
 
 	return &httpClient{
-		Client: c,
+		Client:  c,
+		metrics: m,
 	}
 }
 
 // GET takes in url and the res interface
 // and returns resty Response and error
 func (c *httpClient) GET(url string, res interface{}) (*resty.Response, error) {
-	req := c.Client.R().SetResult(res)
+	attempts := 0
+	req := c.Client.R().SetResult(res).SetContext(withAttempts(&attempts))
+	start := time.Now()
 	httpResp, err := req.Get(url)
-	return httpResp, errIfHTTPReqFailed(httpResp, err)
+	err = errIfHTTPReqFailed(httpResp, err)
+	c.metrics.call(url, time.Since(start), attempts, httpResp, err)
+	return httpResp, err
+}
+
+// StatusError is the error of a non 2XX response
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received non 2XX response: %s", e.Status)
 }
 
 func errIfHTTPReqFailed(resp *resty.Response, err error) error {
@@ -92,7 +163,7 @@ func errIfHTTPReqFailed(resp *resty.Response, err error) error {
 	}
 
 	if !resp.IsSuccess() {
-		return fmt.Errorf("received non 2XX response: %s", resp.Status())
+		return &StatusError{Code: resp.StatusCode(), Status: resp.Status()}
 	}
 
 	return nil
@@ -101,20 +172,33 @@ func errIfHTTPReqFailed(resp *resty.Response, err error) error {
 // buildLatestRateURL builds the /latest url given currency
 // Note: EUR is always the base so can get the value of 1
 // 'currency' in euros
-func buildLatestRateURL(currency string) (string, error) {
+func buildLatestRateURL(baseURL, currency string) (string, error) {
+	queryParams := map[string]string{
+		ParamBase:    currency,
+		ParamSymbols: SymbolEuro,
+	}
+
+	return buildURL(baseURL, PathLatest, queryParams)
+}
+
+// buildHistoricalRateURL builds the /{date} url given currency
+// and date
+// Note: EUR is always the base so can get the value of 1
+// 'currency' in euros
+func buildHistoricalRateURL(baseURL, currency string, date string) (string, error) {
 	queryParams := map[string]string{
 		ParamBase:    currency,
 		ParamSymbols: SymbolEuro,
 	}
 
-	return buildURL(PathLatest, queryParams)
+	return buildURL(baseURL, date, queryParams)
 }
 
 // buildHistoricalRatesURL builds the /history url given currency
 // startDate and endDate
 // Note: EUR is always the base so can get the value of 1
 // 'currency' in euros
-func buildHistoricalRatesURL(currency string, startDate, endDate string) (string, error) {
+func buildHistoricalRatesURL(baseURL, currency string, startDate, endDate string) (string, error) {
 	queryParams := map[string]string{
 		ParamStartDate: startDate,
 		ParamEndDate:   endDate,
@@ -122,17 +206,19 @@ func buildHistoricalRatesURL(currency string, startDate, endDate string) (string
 		ParamBase:      currency,
 	}
 
-	return buildURL(PathHistory, queryParams)
+	return buildURL(baseURL, PathHistory, queryParams)
 }
 
-func buildURL(path string, queryParams map[string]string) (string, error) {
-	base, err := url.Parse(BaseEndpoint)
+// buildURL joins the path to the path of the base URL,
+// e.g. https://host/v1 and latest to https://host/v1/latest
+func buildURL(baseURL, path string, queryParams map[string]string) (string, error) {
+	base, err := url.Parse(baseURL)
 	if err != nil {
 		return "", err
 	}
 
 	// latest path params
-	base.Path += path
+	base.Path = strings.TrimSuffix(base.Path, "/") + "/" + path
 
 	// Query params
 	params := url.Values{}
@@ -83,11 +83,69 @@ func TestBuildLatestRateURL(t *testing.T) {
 	currency := "GBP"
 	expected := "https://api.exchangeratesapi.io/latest?base=GBP&symbols=EUR"
 
-	latestRateURL, err := buildLatestRateURL(currency)
+	latestRateURL, err := buildLatestRateURL(BaseEndpoint, currency)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, latestRateURL, "latest rate URL is wrong")
 }
 
+// TestBuildURLBasePath tests the path is joined to the path of the base URL
+//
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- the path is appended to the path of the base URL after a single '/'
+func TestBuildURLBasePath(t *testing.T) {
+	type testParams struct {
+		description string
+		baseURL     string
+		expected    string
+	}
+
+	cases := []testParams{
+		{
+			description: "base URL without a path",
+			baseURL:     "https://api.exchangeratesapi.io",
+			expected:    "https://api.exchangeratesapi.io/latest?base=GBP&symbols=EUR",
+		},
+		{
+			description: "base URL with a path",
+			baseURL:     "https://rates.example.com/v1",
+			expected:    "https://rates.example.com/v1/latest?base=GBP&symbols=EUR",
+		},
+		{
+			description: "base URL with a path ending in a slash",
+			baseURL:     "https://rates.example.com/v1/",
+			expected:    "https://rates.example.com/v1/latest?base=GBP&symbols=EUR",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			latestRateURL, err := buildLatestRateURL(tt.baseURL, "GBP")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, latestRateURL, "latest rate URL is wrong")
+		})
+	}
+}
+
+// TestBuildHistoricalRateURL tests URL with the date endpoint is built
+//
+// Scenario:
+// 	- given a currency and a date
+//
+// Expect:
+// 	- baseURL is built with the date as the path, with currency as the
+//    'base' and EUR as the 'symbols'
+//  - no error is return
+func TestBuildHistoricalRateURL(t *testing.T) {
+	expected := "https://api.exchangeratesapi.io/2019-11-22?base=GBP&symbols=EUR"
+
+	historicalRateURL, err := buildHistoricalRateURL(BaseEndpoint, "GBP", "2019-11-22")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, historicalRateURL, "historical rate URL is wrong")
+}
+
 // TestBuildHistoricalRatesURL tests URL with the history endpoint is built
 //
 // Scenario:
@@ -104,7 +162,7 @@ func TestBuildHistoricalRatesURL(t *testing.T) {
 	endDate := "2019-11-22"
 	expected := "https://api.exchangeratesapi.io/history?base=GBP&end_at=2019-11-22&start_at=2019-11-15&symbols=EUR"
 
-	latestRateURL, err := buildHistoricalRatesURL(currency, startDate, endDate)
+	latestRateURL, err := buildHistoricalRatesURL(BaseEndpoint, currency, startDate, endDate)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, latestRateURL, "historical rates URL is wrong")
 }
@@ -179,7 +237,24 @@ func TestGET(t *testing.T) {
 	ts := httptest.NewServer(http.Handler(handler))
 	defer ts.Close()
 
-	c := NewHTTPClient()
+	c := NewHTTPClient(DefaultSettings())
 	_, err := c.GET(ts.URL, "result")
 	assert.NoError(t, err)
 }
+
+// TestSettingsValidate checks inconsistent settings are rejected
+func TestSettingsValidate(t *testing.T) {
+	assert.NoError(t, DefaultSettings().Validate())
+
+	s := DefaultSettings()
+	s.RetryCount = -1
+	assert.Error(t, s.Validate())
+
+	s = DefaultSettings()
+	s.RetryMaxWaitTime = s.RetryWaitTime / 2
+	assert.Error(t, s.Validate())
+
+	s = DefaultSettings()
+	s.Timeout = 0
+	assert.Error(t, s.Validate())
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// EndpointHistorical is the endpoint label of the
+	// requests of the rates published on a date
+	EndpointHistorical = "historical"
+
+	// CacheHit and CacheMiss are the result labels
+	// of the requests served by the CachedForex
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// Metrics records the calls to the api and the cache
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+	cache    *prometheus.CounterVec
+}
+
+// NewMetrics registers the metrics of the client in r,
+// it panics if they are already registered
+func NewMetrics(r prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xe_upstream_requests_total",
+			Help: "Number of calls to the provider by endpoint and status code.",
+		}, []string{"provider", "endpoint", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "xe_upstream_request_duration_seconds",
+			Help:    "Latency of the calls to the provider, retries included, by endpoint.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"provider", "endpoint"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xe_upstream_errors_total",
+			Help: "Number of failed calls to the provider by endpoint.",
+		}, []string{"provider", "endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xe_upstream_retries_total",
+			Help: "Number of retried requests to the provider by endpoint.",
+		}, []string{"provider", "endpoint"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xe_cache_requests_total",
+			Help: "Number of requests of rates to the cache by endpoint and result.",
+		}, []string{"endpoint", "result"}),
+	}
+	r.MustRegister(m.requests, m.duration, m.errors, m.retries, m.cache)
+	return m
+}
+
+// call records a call to the rawURL which took d and
+// was sent a number of times, a nil Metrics records nothing
+func (m *Metrics) call(rawURL string, d time.Duration, attempts int, resp *resty.Response, err error) {
+	if m == nil {
+		return
+	}
+
+	provider, endpoint := "", ""
+	if u, err := url.Parse(rawURL); err == nil {
+		provider, endpoint = u.Host, endpointOf(strings.TrimPrefix(u.Path, "/"))
+	}
+
+	code := "error"
+	if resp != nil && resp.StatusCode() != 0 {
+		code = strconv.Itoa(resp.StatusCode())
+	}
+
+	m.requests.WithLabelValues(provider, endpoint, code).Inc()
+	m.duration.WithLabelValues(provider, endpoint).Observe(d.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(provider, endpoint).Inc()
+	}
+	if attempts > 1 {
+		m.retries.WithLabelValues(provider, endpoint).Add(float64(attempts - 1))
+	}
+}
+
+// cached records a request to the cache of the
+// endpoint, a nil Metrics records nothing
+func (m *Metrics) cached(endpoint string, hit bool) {
+	if m == nil {
+		return
+	}
+
+	result := CacheMiss
+	if hit {
+		result = CacheHit
+	}
+	m.cache.WithLabelValues(endpoint, result).Inc()
+}
+
+// endpointOf returns the endpoint label of the path of
+// a request, the rates of a date are labelled historical
+// rather than by date to keep the number of series bounded
+func endpointOf(path string) string {
+	switch path = strings.TrimSuffix(path, "/"); {
+	case strings.HasSuffix(path, PathLatest):
+		return PathLatest
+	case strings.HasSuffix(path, PathHistory):
+		return PathHistory
+	default:
+		return EndpointHistorical
+	}
+}
+
+// attemptsKey is the context key of the
+// number of times a request was sent
+type attemptsKey struct{}
+
+// countAttempt counts the requests sent by resty,
+// it is called before the request and each retry
+func countAttempt(_ *resty.Client, r *resty.Request) error {
+	if n, ok := r.Context().Value(attemptsKey{}).(*int); ok {
+		*n++
+	}
+	return nil
+}
+
+// withAttempts returns a context counting the
+// times a request is sent in attempts
+func withAttempts(attempts *int) context.Context {
+	return context.WithValue(context.Background(), attemptsKey{}, attempts)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	clientmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstrumentedHTTPClient checks the calls to the api are recorded
+// Scenario:
+// 	- the first request of the latest rate is unavailable and is retried
+// 	- the rate of a date is not found
+//
+// Expect:
+// 	- the calls are counted by provider, endpoint and status code
+// 	- the retry and the failed call are counted
+func TestInstrumentedHTTPClient(t *testing.T) {
+	var calls int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, PathLatest) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"rates":{"EUR":0.9},"base":"USD","date":"2019-11-22"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	m := NewMetrics(prometheus.NewRegistry())
+	c := NewInstrumentedHTTPClient(Settings{
+		RetryCount:       2,
+		RetryWaitTime:    time.Millisecond,
+		RetryMaxWaitTime: time.Millisecond,
+		Timeout:          time.Second,
+	}, m)
+	c.(*httpClient).AddRetryCondition(func(r *resty.Response, _ error) bool {
+		return r.StatusCode() == http.StatusServiceUnavailable
+	})
+	fx := NewForex(c, ts.URL+"/")
+
+	_, err := fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+	_, err = fx.GetHistoricalRate("USD", "2019-11-21")
+	assert.Error(t, err)
+
+	provider := strings.TrimPrefix(ts.URL, "http://")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(provider, PathLatest, "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.retries.WithLabelValues(provider, PathLatest)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.errors.WithLabelValues(provider, PathLatest)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(provider, EndpointHistorical, "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.errors.WithLabelValues(provider, EndpointHistorical)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.retries.WithLabelValues(provider, EndpointHistorical)))
+	assert.Equal(t, uint64(1), sampleCount(t, m.duration.WithLabelValues(provider, PathLatest)))
+}
+
+// TestCachedForexMetrics checks the hits and misses of the cache are recorded
+func TestCachedForexMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := clientmock.NewMockForex(ctrl)
+
+	m := NewMetrics(prometheus.NewRegistry())
+	fx := NewCachedForex(mockFX, DefaultCacheTTLs(), m)
+
+	history := &model.HistoricalRates{Base: "USD"}
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-15", "2019-11-22").Return(history, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := fx.GetHistoricalRates("USD", "2019-11-15", "2019-11-22")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.cache.WithLabelValues(PathHistory, CacheMiss)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.cache.WithLabelValues(PathHistory, CacheHit)))
+}
+
+// sampleCount returns the number of
+// observations of the histogram o
+func sampleCount(t *testing.T, o prometheus.Observer) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, o.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
@@ -33,6 +33,21 @@ func (m *MockForex) EXPECT() *MockForexMockRecorder {
 	return m.recorder
 }
 
+// GetHistoricalRate mocks base method
+func (m *MockForex) GetHistoricalRate(arg0, arg1 string) (*model.LatestRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoricalRate", arg0, arg1)
+	ret0, _ := ret[0].(*model.LatestRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoricalRate indicates an expected call of GetHistoricalRate
+func (mr *MockForexMockRecorder) GetHistoricalRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoricalRate", reflect.TypeOf((*MockForex)(nil).GetHistoricalRate), arg0, arg1)
+}
+
 // GetHistoricalRates mocks base method
 func (m *MockForex) GetHistoricalRates(arg0, arg1, arg2 string) (*model.HistoricalRates, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,732 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/client"
+	"github.com/jeffreyyong/xe/server"
+	"github.com/jeffreyyong/xe/store"
+	"github.com/jeffreyyong/xe/tracking"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultAddr is the default address
+	// the service listens on
+	DefaultAddr = "localhost:3030"
+
+	// FlagConfig is the flag of the config file
+	FlagConfig = "config"
+
+	// DefaultCheckTTL is the default time the outcome
+	// of the readiness check of the api is cached
+	DefaultCheckTTL = 30 * time.Second
+
+	// DefaultWatchEvery is the default interval
+	// the config file is checked for changes
+	DefaultWatchEvery = 10 * time.Second
+
+	// EnvPrefix prefixes the environment variables of the
+	// config, e.g. XE_ADDR, EnvConfig is the one of the file
+	EnvPrefix = "XE_"
+	EnvConfig = EnvPrefix + "CONFIG"
+)
+
+// Config holds the settings of the service
+type Config struct {
+	// Addr is the address the service listens on
+	Addr string `yaml:"addr"`
+
+	// ShutdownTimeout is the time the requests
+	// in flight are given to finish on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	Provider Provider `yaml:"provider"`
+	Cache    Cache    `yaml:"cache"`
+
+	// Window, MaxWindow and Strategy are the
+	// lookback windows and default strategy of
+	// the recommendation, see server.Settings
+	Window    int    `yaml:"window"`
+	MaxWindow int    `yaml:"max_window"`
+	Strategy  string `yaml:"strategy"`
+
+	// Experiment is the JSON file of the experiment
+	// assigning callers to arms, no experiment runs if empty
+	Experiment string `yaml:"experiment"`
+
+	// Hysteresis damps recommendations flipping on a weak
+	// signal, the last signals are saved in the SignalStore
+	// file or kept in memory if empty
+	Hysteresis  bool   `yaml:"hysteresis"`
+	SignalStore string `yaml:"signal_store"`
+
+	// OutcomeStore is the file the outcomes of the
+	// recommendations are saved in, kept in memory if
+	// empty, they are scored OutcomeHorizon days after
+	// they are served, every ScoreEvery. Only the latest
+	// OutcomeLimit scored outcomes are kept, all if 0.
+	OutcomeStore   string        `yaml:"outcome_store"`
+	OutcomeHorizon int           `yaml:"outcome_horizon"`
+	OutcomeLimit   int           `yaml:"outcome_limit"`
+	ScoreEvery     time.Duration `yaml:"score_every"`
+
+	// Strategies tunes the built-in strategies
+	Strategies Strategies `yaml:"strategies"`
+
+	// WatchEvery is the interval the config file is checked
+	// for changes to reload, 0 only reloads on SIGHUP
+	WatchEvery time.Duration `yaml:"watch_every"`
+
+	// File is the config file the Config was loaded from
+	File string `yaml:"-"`
+}
+
+// Provider holds the settings of the
+// exchange rates api and of calling it
+type Provider struct {
+	BaseURL          string        `yaml:"base_url"`
+	RetryCount       int           `yaml:"retry_count"`
+	RetryWaitTime    time.Duration `yaml:"retry_wait_time"`
+	RetryMaxWaitTime time.Duration `yaml:"retry_max_wait_time"`
+	Timeout          time.Duration `yaml:"timeout"`
+
+	// BreakerFailures and BreakerCooldown are when the
+	// circuit breaker opens and how long it stays open
+	BreakerFailures int           `yaml:"breaker_failures"`
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown"`
+
+	// CheckTTL is the time the outcome of
+	// the readiness check of the api is cached
+	CheckTTL time.Duration `yaml:"check_ttl"`
+}
+
+// Cache holds how long the responses
+// of the provider are cached
+type Cache struct {
+	LatestTTL  time.Duration `yaml:"latest_ttl"`
+	HistoryTTL time.Duration `yaml:"history_ttl"`
+}
+
+// Strategies holds the tunable parameters
+// of the built-in strategies
+type Strategies struct {
+	TrendThresholdMode       string  `yaml:"trend_threshold_mode"`
+	TrendThreshold           float64 `yaml:"trend_threshold"`
+	TrendStrongThreshold     float64 `yaml:"trend_strong_threshold"`
+	TrendTimeAxis            string  `yaml:"trend_time_axis"`
+	MovingAverageShortPeriod int     `yaml:"moving_average_short_period"`
+	MovingAverageLongPeriod  int     `yaml:"moving_average_long_period"`
+	MovingAverageType        string  `yaml:"moving_average_type"`
+	ZScoreThreshold          float64 `yaml:"zscore_threshold"`
+	RSIPeriod                int     `yaml:"rsi_period"`
+	RSIOverbought            float64 `yaml:"rsi_overbought"`
+	RSIOversold              float64 `yaml:"rsi_oversold"`
+	BollingerPeriod          int     `yaml:"bollinger_period"`
+	BollingerWidth           float64 `yaml:"bollinger_width"`
+	ForecastMethod           string  `yaml:"forecast_method"`
+	ForecastDays             int     `yaml:"forecast_days"`
+	ForecastConfidence       float64 `yaml:"forecast_confidence"`
+	HoltAlpha                float64 `yaml:"holt_alpha"`
+	HoltBeta                 float64 `yaml:"holt_beta"`
+	AROrder                  int     `yaml:"ar_order"`
+	SimulationMethod         string  `yaml:"simulation_method"`
+	SimulationDays           int     `yaml:"simulation_days"`
+	SimulationPaths          int     `yaml:"simulation_paths"`
+	SimulationSeed           int     `yaml:"simulation_seed"`
+	SimulationThreshold      float64 `yaml:"simulation_threshold"`
+	KalmanLevelNoise         float64 `yaml:"kalman_level_noise"`
+	KalmanSlopeNoise         float64 `yaml:"kalman_slope_noise"`
+	KalmanThreshold          float64 `yaml:"kalman_threshold"`
+	KalmanStrongThreshold    float64 `yaml:"kalman_strong_threshold"`
+	EnsembleVote             string  `yaml:"ensemble_vote"`
+
+	// EnsembleWeights is a comma separated list of
+	// strategy=weight, e.g. trend=2,kalman=0.5, the
+	// other members have a weight of 1
+	EnsembleWeights string `yaml:"ensemble_weights"`
+}
+
+// DefaultStrategies returns the defaults of the calculator
+func DefaultStrategies() Strategies {
+	p := calculator.DefaultStrategyParams()
+	return Strategies{
+		TrendThresholdMode:       string(p.TrendThresholdMode),
+		TrendThreshold:           p.TrendThreshold,
+		TrendStrongThreshold:     p.TrendStrongThreshold,
+		TrendTimeAxis:            string(p.TrendAxis),
+		MovingAverageShortPeriod: p.ShortPeriod,
+		MovingAverageLongPeriod:  p.LongPeriod,
+		MovingAverageType:        string(p.Average),
+		ZScoreThreshold:          p.ZScoreThreshold,
+		RSIPeriod:                p.RSIPeriod,
+		RSIOverbought:            p.RSIOverbought,
+		RSIOversold:              p.RSIOversold,
+		BollingerPeriod:          p.BollingerPeriod,
+		BollingerWidth:           p.BollingerWidth,
+		ForecastMethod:           string(p.ForecastMethod),
+		ForecastDays:             p.ForecastDays,
+		ForecastConfidence:       p.ForecastConfidence,
+		HoltAlpha:                p.HoltAlpha,
+		HoltBeta:                 p.HoltBeta,
+		AROrder:                  p.AROrder,
+		SimulationMethod:         string(p.Simulation.Method),
+		SimulationDays:           p.Simulation.Days,
+		SimulationPaths:          p.Simulation.Paths,
+		SimulationSeed:           int(p.Simulation.Seed),
+		SimulationThreshold:      p.SimulationThreshold,
+		KalmanLevelNoise:         p.KalmanLevelNoise,
+		KalmanSlopeNoise:         p.KalmanSlopeNoise,
+		KalmanThreshold:          p.KalmanThreshold,
+		KalmanStrongThreshold:    p.KalmanStrong,
+		EnsembleVote:             string(p.EnsembleVote),
+	}
+}
+
+// Params returns the params of the calculator,
+// it errors if the ensemble weights are malformed
+func (s Strategies) Params() (calculator.StrategyParams, error) {
+	weights, err := parseWeights(s.EnsembleWeights)
+	if err != nil {
+		return calculator.StrategyParams{}, err
+	}
+
+	p := calculator.DefaultStrategyParams()
+	p.TrendThresholdMode = calculator.ThresholdMode(s.TrendThresholdMode)
+	p.TrendThreshold = s.TrendThreshold
+	p.TrendStrongThreshold = s.TrendStrongThreshold
+	p.TrendAxis = calculator.TimeAxis(s.TrendTimeAxis)
+	p.ShortPeriod = s.MovingAverageShortPeriod
+	p.LongPeriod = s.MovingAverageLongPeriod
+	p.Average = calculator.Average(s.MovingAverageType)
+	p.ZScoreThreshold = s.ZScoreThreshold
+	p.RSIPeriod = s.RSIPeriod
+	p.RSIOverbought = s.RSIOverbought
+	p.RSIOversold = s.RSIOversold
+	p.BollingerPeriod = s.BollingerPeriod
+	p.BollingerWidth = s.BollingerWidth
+	p.ForecastMethod = calculator.ForecastMethod(s.ForecastMethod)
+	p.ForecastDays = s.ForecastDays
+	p.ForecastConfidence = s.ForecastConfidence
+	p.HoltAlpha = s.HoltAlpha
+	p.HoltBeta = s.HoltBeta
+	p.AROrder = s.AROrder
+	p.Simulation.Method = calculator.SimulationMethod(s.SimulationMethod)
+	p.Simulation.Days = s.SimulationDays
+	p.Simulation.Paths = s.SimulationPaths
+	p.Simulation.Seed = int64(s.SimulationSeed)
+	p.SimulationThreshold = s.SimulationThreshold
+	p.KalmanLevelNoise = s.KalmanLevelNoise
+	p.KalmanSlopeNoise = s.KalmanSlopeNoise
+	p.KalmanThreshold = s.KalmanThreshold
+	p.KalmanStrong = s.KalmanStrongThreshold
+	p.EnsembleVote = calculator.VoteMode(s.EnsembleVote)
+	p.EnsembleWeights = weights
+	return p, nil
+}
+
+// parseWeights parses the comma separated strategy=weight
+// list of the ensemble weights, nil if it is empty
+func parseWeights(s string) (map[string]float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	weights := map[string]float64{}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("ensemble weights must be strategy=weight, got %q", pair)
+		}
+		name := strings.TrimSpace(kv[0])
+		w, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ensemble weight of %s: %v", name, err)
+		}
+		if _, ok := weights[name]; ok {
+			return nil, fmt.Errorf("duplicate ensemble weight of %s", name)
+		}
+		weights[name] = w
+	}
+	return weights, nil
+}
+
+// Default returns the Config the service
+// runs with when the operator sets nothing
+func Default() Config {
+	c := client.DefaultSettings()
+	b := client.DefaultBreakerSettings()
+	ttls := client.DefaultCacheTTLs()
+	s := server.DefaultSettings()
+
+	return Config{
+		Addr:            DefaultAddr,
+		ShutdownTimeout: server.DefaultShutdownTimeout,
+		Provider: Provider{
+			BaseURL:          client.BaseEndpoint,
+			RetryCount:       c.RetryCount,
+			RetryWaitTime:    c.RetryWaitTime,
+			RetryMaxWaitTime: c.RetryMaxWaitTime,
+			Timeout:          c.Timeout,
+			BreakerFailures:  b.Failures,
+			BreakerCooldown:  b.Cooldown,
+			CheckTTL:         DefaultCheckTTL,
+		},
+		Cache: Cache{
+			LatestTTL:  ttls.Latest,
+			HistoryTTL: ttls.History,
+		},
+		Window:         s.Window,
+		MaxWindow:      s.MaxWindow,
+		Strategy:       s.Strategy,
+		Hysteresis:     true,
+		OutcomeHorizon: tracking.DefaultHorizon,
+		OutcomeLimit:   store.DefaultOutcomeLimit,
+		ScoreEvery:     tracking.DefaultScoreEvery,
+		Strategies:     DefaultStrategies(),
+		WatchEvery:     DefaultWatchEvery,
+	}
+}
+
+// field is a setting of the Config which can be
+// set by a flag or an environment variable
+type field struct {
+	flag  string
+	env   string
+	usage string
+
+	// value points to the setting in the Config, a
+	// *string, *int, *float64, *bool or *time.Duration
+	value interface{}
+}
+
+// fields returns the settings of c which can
+// be set by flags or environment variables
+func fields(c *Config) []field {
+	f := []field{
+		{"addr", "ADDR", "address the service listens on", &c.Addr},
+		{"shutdown-timeout", "SHUTDOWN_TIMEOUT", "time the requests in flight are given to finish on shutdown",
+			&c.ShutdownTimeout},
+		{"provider-base-url", "PROVIDER_BASE_URL", "base URL of the exchange rates api", &c.Provider.BaseURL},
+		{"provider-retry-count", "PROVIDER_RETRY_COUNT", "number of retries of a failed request to the api",
+			&c.Provider.RetryCount},
+		{"provider-retry-wait-time", "PROVIDER_RETRY_WAIT_TIME", "wait time before retrying a failed request",
+			&c.Provider.RetryWaitTime},
+		{"provider-retry-max-wait-time", "PROVIDER_RETRY_MAX_WAIT_TIME",
+			"max wait time before retrying a failed request", &c.Provider.RetryMaxWaitTime},
+		{"provider-timeout", "PROVIDER_TIMEOUT", "timeout of a request to the api", &c.Provider.Timeout},
+		{"provider-breaker-failures", "PROVIDER_BREAKER_FAILURES",
+			"number of failed requests in a row opening the circuit breaker of the api, 0 disables it",
+			&c.Provider.BreakerFailures},
+		{"provider-breaker-cooldown", "PROVIDER_BREAKER_COOLDOWN",
+			"time the circuit breaker stays open before a request is let through", &c.Provider.BreakerCooldown},
+		{"provider-check-ttl", "PROVIDER_CHECK_TTL", "time the outcome of the readiness check of the api is cached",
+			&c.Provider.CheckTTL},
+		{"cache-latest-ttl", "CACHE_LATEST_TTL", "time the latest rates are cached, 0 disables caching",
+			&c.Cache.LatestTTL},
+		{"cache-history-ttl", "CACHE_HISTORY_TTL", "time the historical rates are cached, 0 disables caching",
+			&c.Cache.HistoryTTL},
+		{"window", "WINDOW", "default number of days of historical rates for the recommendation", &c.Window},
+		{"max-window", "MAX_WINDOW", "max number of days of historical rates callers can request", &c.MaxWindow},
+		{"strategy", "STRATEGY", "default strategy for the recommendation", &c.Strategy},
+		{"experiment", "EXPERIMENT",
+			"JSON file of the experiment assigning callers to arms using different strategies", &c.Experiment},
+		{"hysteresis", "HYSTERESIS",
+			"damp recommendations flipping between convert and don't convert on a weak signal", &c.Hysteresis},
+		{"signal-store", "SIGNAL_STORE",
+			"file the last signal per currency and strategy is saved in, kept in memory if empty", &c.SignalStore},
+		{"outcome-store", "OUTCOME_STORE",
+			"file the recommendations served and their outcomes are saved in, kept in memory if empty",
+			&c.OutcomeStore},
+		{"outcome-horizon", "OUTCOME_HORIZON", "number of days after a recommendation its outcome is scored on",
+			&c.OutcomeHorizon},
+		{"outcome-limit", "OUTCOME_LIMIT", "number of the latest scored outcomes kept, all of them if 0",
+			&c.OutcomeLimit},
+		{"score-every", "SCORE_EVERY", "interval between two scorings of the due outcomes", &c.ScoreEvery},
+		{"watch-every", "WATCH_EVERY", "interval the config file is checked for changes, 0 only reloads on SIGHUP",
+			&c.WatchEvery},
+	}
+	return append(f, strategyFields(&c.Strategies)...)
+}
+
+// strategyFields returns the parameters of
+// the strategies which can be set by flags
+func strategyFields(s *Strategies) []field {
+	return []field{
+		{"trend-threshold-mode", "TREND_THRESHOLD_MODE",
+			"how the significance of the trend is measured: absolute, relative or p-value", &s.TrendThresholdMode},
+		{"trend-threshold", "TREND_THRESHOLD", "trends within the threshold are neutral", &s.TrendThreshold},
+		{"trend-strong-threshold", "TREND_STRONG_THRESHOLD", "trends beyond the strong threshold are strong",
+			&s.TrendStrongThreshold},
+		{"trend-time-axis", "TREND_TIME_AXIS",
+			"how the days of the trend are measured: calendar-days or business-days", &s.TrendTimeAxis},
+		{"moving-average-short-period", "MOVING_AVERAGE_SHORT_PERIOD",
+			"number of rates of the short moving average", &s.MovingAverageShortPeriod},
+		{"moving-average-long-period", "MOVING_AVERAGE_LONG_PERIOD",
+			"number of rates of the long moving average", &s.MovingAverageLongPeriod},
+		{"moving-average-type", "MOVING_AVERAGE_TYPE", "how the rates are averaged: sma or ema",
+			&s.MovingAverageType},
+		{"zscore-threshold", "ZSCORE_THRESHOLD",
+			"standard deviations from the mean within which the zscore strategy is neutral", &s.ZScoreThreshold},
+		{"rsi-period", "RSI_PERIOD", "number of daily changes the RSI is smoothed over", &s.RSIPeriod},
+		{"rsi-overbought", "RSI_OVERBOUGHT", "RSI at or above which the rsi strategy recommends to convert",
+			&s.RSIOverbought},
+		{"rsi-oversold", "RSI_OVERSOLD", "RSI at or below which the rsi strategy recommends not to convert",
+			&s.RSIOversold},
+		{"bollinger-period", "BOLLINGER_PERIOD", "number of rates of the Bollinger bands", &s.BollingerPeriod},
+		{"bollinger-width", "BOLLINGER_WIDTH", "standard deviations between the Bollinger bands and their mean",
+			&s.BollingerWidth},
+		{"forecast-method", "FORECAST_METHOD", "how the forecast strategy forecasts the rates: holt or ar",
+			&s.ForecastMethod},
+		{"forecast-days", "FORECAST_DAYS", "number of business days the forecast strategy looks ahead",
+			&s.ForecastDays},
+		{"forecast-confidence", "FORECAST_CONFIDENCE",
+			"probability of the rate being within the prediction interval", &s.ForecastConfidence},
+		{"holt-alpha", "HOLT_ALPHA", "smoothing factor of the level of Holt's method", &s.HoltAlpha},
+		{"holt-beta", "HOLT_BETA", "smoothing factor of the slope of Holt's method", &s.HoltBeta},
+		{"ar-order", "AR_ORDER", "number of previous rates each rate is regressed on by the ar method",
+			&s.AROrder},
+		{"simulation-method", "SIMULATION_METHOD",
+			"how the monte-carlo strategy simulates the rates: gbm or bootstrap", &s.SimulationMethod},
+		{"simulation-days", "SIMULATION_DAYS", "number of business days the monte-carlo strategy simulates",
+			&s.SimulationDays},
+		{"simulation-paths", "SIMULATION_PATHS", "number of paths the monte-carlo strategy simulates",
+			&s.SimulationPaths},
+		{"simulation-seed", "SIMULATION_SEED", "seed of the random numbers of the monte-carlo strategy",
+			&s.SimulationSeed},
+		{"simulation-threshold", "SIMULATION_THRESHOLD",
+			"probability of a better or worse rate beyond which the monte-carlo strategy recommends",
+			&s.SimulationThreshold},
+		{"kalman-level-noise", "KALMAN_LEVEL_NOISE",
+			"variance of the daily change of the level relative to the noise of the rates", &s.KalmanLevelNoise},
+		{"kalman-slope-noise", "KALMAN_SLOPE_NOISE",
+			"variance of the daily change of the slope relative to the noise of the rates", &s.KalmanSlopeNoise},
+		{"kalman-threshold", "KALMAN_THRESHOLD",
+			"standard errors of the filtered slope within which the kalman strategy is neutral", &s.KalmanThreshold},
+		{"kalman-strong-threshold", "KALMAN_STRONG_THRESHOLD",
+			"standard errors of the filtered slope beyond which the kalman strategy is strong",
+			&s.KalmanStrongThreshold},
+		{"ensemble-vote", "ENSEMBLE_VOTE", "how the ensemble strategy counts the votes: majority or weighted",
+			&s.EnsembleVote},
+		{"ensemble-weights", "ENSEMBLE_WEIGHTS",
+			"comma separated strategy=weight of the members of the ensemble, the others weigh 1",
+			&s.EnsembleWeights},
+	}
+}
+
+// set parses s into the setting the value points to
+func set(value interface{}, s string) error {
+	switch v := value.(type) {
+	case *string:
+		*v = s
+	case *int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		*v = n
+	case *float64:
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		*v = x
+	case *bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		*v = b
+	case *time.Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*v = d
+	default:
+		return fmt.Errorf("unsupported setting type %T", value)
+	}
+	return nil
+}
+
+// flagValue records the value of a flag, so
+// only flags set on the command line are applied
+type flagValue struct {
+	def    string
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	if f.set {
+		return f.value
+	}
+	return f.def
+}
+
+func (f *flagValue) Set(s string) error {
+	f.value, f.set = s, true
+	return nil
+}
+
+// IsBoolFlag lets bool flags be set without a value
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// Flags defines the flags of the Config and of the config file
+// on fs, the returned func loads the Config once fs is parsed:
+// 1) starts from the defaults
+// 2) overrides them with the YAML or JSON config file given by
+//    the config flag or the XE_CONFIG environment variable
+// 3) overrides them with the XE_ environment variables
+// 4) overrides them with the flags set on the command line
+// 5) validates the Config
+// lookupEnv looks up the environment variables, e.g. os.LookupEnv.
+// The func can be called again to reload the Config.
+func Flags(fs *flag.FlagSet) func(lookupEnv func(string) (string, bool)) (*Config, error) {
+	defaults := Default()
+	file := fs.String(FlagConfig, "", "YAML or JSON config file, flags and "+EnvPrefix+
+		" environment variables override it")
+	values := defineFlags(fs, fields(&defaults), true)
+
+	return func(lookupEnv func(string) (string, bool)) (*Config, error) {
+		c, err := loadFileAndEnv(*file, lookupEnv)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyFlags(fields(&c), values); err != nil {
+			return nil, err
+		}
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		return &c, nil
+	}
+}
+
+// Load loads the Config like Flags without flags, e.g. for
+// the commands which don't take the flags of the service:
+// the defaults are overridden by the config file, or the
+// file given by the XE_CONFIG environment variable if file
+// is empty, then by the XE_ environment variables
+func Load(file string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	c, err := loadFileAndEnv(file, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// loadFileAndEnv overrides the defaults with the config
+// file and then with the XE_ environment variables
+func loadFileAndEnv(file string, lookupEnv func(string) (string, bool)) (Config, error) {
+	c := Default()
+
+	c.File = file
+	if c.File == "" {
+		c.File, _ = lookupEnv(EnvConfig)
+	}
+	if c.File != "" {
+		if err := c.loadFile(c.File); err != nil {
+			return c, err
+		}
+	}
+
+	for _, f := range fields(&c) {
+		if s, ok := lookupEnv(EnvPrefix + f.env); ok {
+			if err := set(f.value, s); err != nil {
+				return c, fmt.Errorf("invalid %s%s: %v", EnvPrefix, f.env, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// StrategyFlags defines the flags tuning the strategies on fs,
+// the returned func gives the params of s overridden by the
+// flags set once fs is parsed
+func StrategyFlags(fs *flag.FlagSet) func(s Strategies) (calculator.StrategyParams, error) {
+	defaults := DefaultStrategies()
+	values := defineFlags(fs, strategyFields(&defaults), false)
+
+	return func(s Strategies) (calculator.StrategyParams, error) {
+		if err := applyFlags(strategyFields(&s), values); err != nil {
+			return calculator.StrategyParams{}, err
+		}
+		return s.Params()
+	}
+}
+
+// defineFlags defines a flag per field on fs,
+// mentioning its environment variable if env
+func defineFlags(fs *flag.FlagSet, fields []field, env bool) map[string]*flagValue {
+	values := map[string]*flagValue{}
+	for _, f := range fields {
+		_, isBool := f.value.(*bool)
+		v := &flagValue{def: fmt.Sprint(deref(f.value)), isBool: isBool}
+		values[f.flag] = v
+
+		usage := f.usage
+		if env {
+			usage += " (env " + EnvPrefix + f.env + ")"
+		}
+		fs.Var(v, f.flag, usage)
+	}
+	return values
+}
+
+// applyFlags sets the fields whose flags are set
+func applyFlags(fields []field, values map[string]*flagValue) error {
+	for _, f := range fields {
+		if v := values[f.flag]; v.set {
+			if err := set(f.value, v.value); err != nil {
+				return fmt.Errorf("invalid -%s: %v", f.flag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deref returns the setting the value points to
+func deref(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *string:
+		return *v
+	case *int:
+		return *v
+	case *float64:
+		return *v
+	case *bool:
+		return *v
+	case *time.Duration:
+		return *v
+	default:
+		return nil
+	}
+}
+
+// loadFile overrides the Config with the settings of the YAML
+// or JSON file at path, unknown settings are rejected
+func (c *Config) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return fmt.Errorf("decoding config %s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate checks the Config is consistent
+func (c *Config) Validate() error {
+	if c.Addr == "" {
+		return fmt.Errorf("addr must be provided")
+	}
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("shutdown timeout must be positive, got %v", c.ShutdownTimeout)
+	}
+	u, err := url.Parse(c.Provider.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("provider base URL must be an http or https URL, got %q", c.Provider.BaseURL)
+	}
+	if strings.ContainsAny(c.Provider.BaseURL, "?#") {
+		return fmt.Errorf("provider base URL must not have a query or a fragment, got %q", c.Provider.BaseURL)
+	}
+	if err := c.ClientSettings().Validate(); err != nil {
+		return err
+	}
+	if err := c.BreakerSettings().Validate(); err != nil {
+		return err
+	}
+	if c.Provider.CheckTTL < 0 {
+		return fmt.Errorf("check TTL must not be negative, got %v", c.Provider.CheckTTL)
+	}
+	if err := c.CacheTTLs().Validate(); err != nil {
+		return err
+	}
+	if c.OutcomeHorizon < 1 || c.ScoreEvery <= 0 {
+		return fmt.Errorf("outcome horizon and score interval must be positive, got %d and %v",
+			c.OutcomeHorizon, c.ScoreEvery)
+	}
+	if c.OutcomeLimit < 0 {
+		return fmt.Errorf("outcome limit must not be negative, got %d", c.OutcomeLimit)
+	}
+	if c.WatchEvery < 0 {
+		return fmt.Errorf("watch interval must not be negative, got %v", c.WatchEvery)
+	}
+	if err := c.Settings().Validate(); err != nil {
+		return err
+	}
+
+	p, err := c.Strategies.Params()
+	if err != nil {
+		return fmt.Errorf("invalid strategy params: %v", err)
+	}
+	strategies, err := calculator.NewStrategies(p)
+	if err != nil {
+		return fmt.Errorf("invalid strategy params: %v", err)
+	}
+	for name := range p.EnsembleWeights {
+		if _, ok := strategies.Get(name); !ok || name == calculator.StrategyEnsemble {
+			return fmt.Errorf("invalid strategy params: ensemble weight of unknown member %s", name)
+		}
+	}
+	if _, err := calculator.NewForecasters(p); err != nil {
+		return fmt.Errorf("invalid forecast params: %v", err)
+	}
+	if _, ok := strategies.Get(c.Strategy); !ok {
+		return fmt.Errorf("unknown strategy: %s", c.Strategy)
+	}
+	return nil
+}
+
+// ClientSettings returns the settings of the HTTP client
+func (c *Config) ClientSettings() client.Settings {
+	return client.Settings{
+		RetryCount:       c.Provider.RetryCount,
+		RetryWaitTime:    c.Provider.RetryWaitTime,
+		RetryMaxWaitTime: c.Provider.RetryMaxWaitTime,
+		Timeout:          c.Provider.Timeout,
+	}
+}
+
+// BreakerSettings returns the settings of the circuit breaker
+func (c *Config) BreakerSettings() client.BreakerSettings {
+	return client.BreakerSettings{
+		Failures: c.Provider.BreakerFailures,
+		Cooldown: c.Provider.BreakerCooldown,
+	}
+}
+
+// CacheTTLs returns the TTLs of the responses of the provider
+func (c *Config) CacheTTLs() client.CacheTTLs {
+	return client.CacheTTLs{
+		Latest:  c.Cache.LatestTTL,
+		History: c.Cache.HistoryTTL,
+	}
+}
+
+// Settings returns the settings of the server Handler
+func (c *Config) Settings() server.Settings {
+	s := server.DefaultSettings()
+	s.Window = c.Window
+	s.MaxWindow = c.MaxWindow
+	s.Strategy = c.Strategy
+	return s
+}
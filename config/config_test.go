@@ -0,0 +1,294 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// env returns a lookupEnv of the variables
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+// writeConfig writes the config file in a temp dir
+func writeConfig(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// load parses the args and loads the Config
+func load(args []string, vars map[string]string) (*Config, error) {
+	fs := flag.NewFlagSet("xe", flag.ContinueOnError)
+	loadConfig := Flags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return loadConfig(env(vars))
+}
+
+// TestDefault checks the defaults are valid
+// and match the defaults of the packages
+func TestDefault(t *testing.T) {
+	c, err := load(nil, nil)
+	assert.NoError(t, err)
+
+	exp := Default()
+	assert.Equal(t, &exp, c)
+	assert.Equal(t, DefaultAddr, c.Addr)
+	assert.Equal(t, client.DefaultSettings(), c.ClientSettings())
+	assert.Equal(t, client.DefaultCacheTTLs(), c.CacheTTLs())
+	assert.Equal(t, client.DefaultBreakerSettings(), c.BreakerSettings())
+}
+
+// TestPrecedence checks the file overrides the defaults, the
+// environment overrides the file and flags override the environment
+// Scenario:
+// 	- a YAML file sets the addr, timeout, window and strategy
+// 	- the environment sets the timeout and window
+// 	- a flag sets the window
+//
+// Expect:
+// 	- addr and strategy of the file, timeout of the environment
+// 	  and window of the flag
+// 	- settings set nowhere keep their defaults
+func TestPrecedence(t *testing.T) {
+	path, cleanup := writeConfig(t, "xe.yaml", `
+addr: ":8080"
+provider:
+  timeout: 2s
+cache:
+  latest_ttl: 30s
+window: 14
+strategy: kalman
+`)
+	defer cleanup()
+
+	vars := map[string]string{
+		"XE_PROVIDER_TIMEOUT": "3s",
+		"XE_WINDOW":           "21",
+	}
+	c, err := load([]string{"-config", path, "-window", "30", "-hysteresis"}, vars)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":8080", c.Addr)
+	assert.Equal(t, "kalman", c.Strategy)
+	assert.Equal(t, 3*time.Second, c.Provider.Timeout)
+	assert.Equal(t, 30*time.Second, c.Cache.LatestTTL)
+	assert.Equal(t, 30, c.Window)
+	assert.Equal(t, client.DefaultRetryCount, c.Provider.RetryCount)
+	assert.Equal(t, client.DefaultHistoryTTL, c.Cache.HistoryTTL)
+	assert.Equal(t, 30, c.Settings().Window)
+	assert.True(t, c.Hysteresis)
+}
+
+// TestJSONFileFromEnv checks the config file can be JSON
+// and be given by the XE_CONFIG environment variable
+func TestJSONFileFromEnv(t *testing.T) {
+	path, cleanup := writeConfig(t, "xe.json", `{
+		"provider": {"base_url": "http://localhost:8081", "retry_count": 0},
+		"max_window": 90
+	}`)
+	defer cleanup()
+
+	c, err := load([]string{"-hysteresis=false"}, map[string]string{EnvConfig: path})
+	assert.NoError(t, err)
+	assert.False(t, c.Hysteresis)
+	assert.Equal(t, "http://localhost:8081", c.Provider.BaseURL)
+	assert.Equal(t, 0, c.Provider.RetryCount)
+	assert.Equal(t, 90, c.MaxWindow)
+}
+
+// TestInvalid checks invalid configs are rejected
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- error is returned
+func TestInvalid(t *testing.T) {
+	type testParams struct {
+		description string
+		file        string
+		args        []string
+		vars        map[string]string
+	}
+
+	cases := []testParams{
+		{
+			description: "unknown setting in the file",
+			file:        "timeout: 1s",
+		},
+		{
+			description: "malformed file",
+			file:        "window: [",
+		},
+		{
+			description: "malformed duration in the environment",
+			vars:        map[string]string{"XE_PROVIDER_TIMEOUT": "soon"},
+		},
+		{
+			description: "malformed number flag",
+			args:        []string{"-window", "week"},
+		},
+		{
+			description: "window above max window",
+			args:        []string{"-window", "30", "-max-window", "7"},
+		},
+		{
+			description: "base URL without scheme",
+			args:        []string{"-provider-base-url", "api.exchangeratesapi.io"},
+		},
+		{
+			description: "base URL with a query",
+			args:        []string{"-provider-base-url", "https://api.exchangeratesapi.io?key=1"},
+		},
+		{
+			description: "base URL with a fragment",
+			args:        []string{"-provider-base-url", "https://api.exchangeratesapi.io/v1#rates"},
+		},
+		{
+			description: "negative TTL",
+			vars:        map[string]string{"XE_CACHE_HISTORY_TTL": "-1h"},
+		},
+		{
+			description: "zero timeout",
+			args:        []string{"-provider-timeout", "0s"},
+		},
+		{
+			description: "zero breaker cooldown",
+			args:        []string{"-provider-breaker-cooldown", "0s"},
+		},
+		{
+			description: "zero outcome horizon",
+			args:        []string{"-outcome-horizon", "0"},
+		},
+		{
+			description: "negative outcome limit",
+			args:        []string{"-outcome-limit", "-1"},
+		},
+		{
+			description: "malformed bool",
+			vars:        map[string]string{"XE_HYSTERESIS": "maybe"},
+		},
+		{
+			description: "invalid strategy param",
+			args:        []string{"-kalman-threshold", "-1"},
+		},
+		{
+			description: "invalid moving average type",
+			args:        []string{"-moving-average-type", "wma"},
+		},
+		{
+			description: "malformed ensemble weights",
+			vars:        map[string]string{"XE_ENSEMBLE_WEIGHTS": "trend:2"},
+		},
+		{
+			description: "ensemble weight of an unknown member",
+			args:        []string{"-ensemble-weights", "trend=2,astrology=1"},
+		},
+		{
+			description: "unknown default strategy",
+			vars:        map[string]string{"XE_STRATEGY": "coin-flip"},
+		},
+		{
+			description: "zero shutdown timeout",
+			vars:        map[string]string{"XE_SHUTDOWN_TIMEOUT": "0s"},
+		},
+		{
+			description: "empty addr",
+			args:        []string{"-addr", ""},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				path, cleanup := writeConfig(t, "xe.yaml", tt.file)
+				defer cleanup()
+				args = append([]string{"-config", path}, args...)
+			}
+
+			_, err := load(args, tt.vars)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := load([]string{"-config", "missing.yaml"}, nil)
+	assert.Error(t, err)
+}
+
+// TestStrategyFlags checks the strategy flags set the
+// params and the other params keep their defaults
+func TestStrategyFlags(t *testing.T) {
+	fs := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	params := StrategyFlags(fs)
+	assert.NoError(t, fs.Parse([]string{"-trend-time-axis", "business-days", "-kalman-threshold", "1.5",
+		"-rsi-period", "10", "-ar-order", "3", "-ensemble-vote", "weighted", "-ensemble-weights", "trend=2, kalman=0.5"}))
+
+	p, err := params(DefaultStrategies())
+	assert.NoError(t, err)
+	exp := calculator.DefaultStrategyParams()
+	exp.TrendAxis = calculator.AxisBusinessDays
+	exp.KalmanThreshold = 1.5
+	exp.RSIPeriod = 10
+	exp.AROrder = 3
+	exp.EnsembleVote = calculator.VoteWeighted
+	exp.EnsembleWeights = map[string]float64{"trend": 2, "kalman": 0.5}
+	assert.Equal(t, exp, p)
+	p, err = DefaultStrategies().Params()
+	assert.NoError(t, err)
+	assert.Equal(t, calculator.DefaultStrategyParams(), p)
+
+	s := DefaultStrategies()
+	s.KalmanThreshold, s.ForecastDays = 2.5, 9
+	p, err = params(s)
+	assert.NoError(t, err)
+	exp.ForecastDays = 9
+	assert.Equal(t, exp, p, "flags don't override the strategies given")
+}
+
+// TestLoad checks the Config is loaded from the file
+// and the environment without flags
+// Scenario:
+// 	- the XE_CONFIG environment variable gives a YAML file
+// 	  setting the base URL of the provider and the timeout
+// 	- the environment sets the timeout
+//
+// Expect:
+// 	- base URL of the file and timeout of the environment
+// 	- an invalid file is rejected
+func TestLoad(t *testing.T) {
+	path, cleanup := writeConfig(t, "xe.yaml", `
+provider:
+  base_url: http://localhost:8000
+  timeout: 2s
+`)
+	defer cleanup()
+
+	vars := map[string]string{
+		EnvConfig:             path,
+		"XE_PROVIDER_TIMEOUT": "3s",
+	}
+	c, err := Load("", env(vars))
+	assert.NoError(t, err)
+	assert.Equal(t, path, c.File)
+	assert.Equal(t, "http://localhost:8000", c.Provider.BaseURL)
+	assert.Equal(t, 3*time.Second, c.Provider.Timeout)
+
+	_, err = Load("missing.yaml", env(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// restart holds the settings which are only applied on
+// restart, they are kept when the Config is reloaded
+var restart = map[string]bool{
+	"addr":                      true,
+	"shutdown-timeout":          true,
+	"provider-breaker-failures": true,
+	"provider-breaker-cooldown": true,
+	"provider-check-ttl":        true,
+	"hysteresis":                true,
+	"signal-store":              true,
+	"outcome-store":             true,
+	"outcome-horizon":           true,
+	"outcome-limit":             true,
+	"score-every":               true,
+	"watch-every":               true,
+}
+
+// Change is a setting changed by a reload
+type Change struct {
+	Setting string
+	Old     interface{}
+	New     interface{}
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Setting, c.Old, c.New)
+}
+
+// Diff returns the settings which differ between the
+// Configs, named by their flags, in the order of the flags
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	oldFields, newFields := fields(old), fields(new)
+	for i, f := range newFields {
+		o, n := deref(oldFields[i].value), deref(f.value)
+		if o != n {
+			changes = append(changes, Change{Setting: f.flag, Old: o, New: n})
+		}
+	}
+	if old.File != new.File {
+		changes = append(changes, Change{Setting: FlagConfig, Old: old.File, New: new.File})
+	}
+	return changes
+}
+
+// Watcher reloads the Config on SIGHUP or when its file changes
+type Watcher struct {
+	load  func() (*Config, error)
+	apply func(*Config) error
+
+	mu      sync.Mutex
+	current *Config
+	modTime time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewWatcher initialises a Watcher of the current Config,
+// load loads the Config again and apply applies the reloaded
+// Config to the service, rejecting it if it returns an error
+func NewWatcher(current *Config, load func() (*Config, error), apply func(*Config) error) (*Watcher, error) {
+	if current == nil || load == nil || apply == nil {
+		return nil, fmt.Errorf("config, load and apply must be provided")
+	}
+
+	return &Watcher{
+		load:    load,
+		apply:   apply,
+		current: current,
+		modTime: modTime(current.File),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Current returns the Config the service runs with
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Run reloads the Config on every signal of hup and, if
+// it has a file, when the file is modified, until Stop
+func (w *Watcher) Run(hup <-chan os.Signal) {
+	defer close(w.done)
+
+	var tick <-chan time.Time
+	if every := w.Current().WatchEvery; every > 0 && w.Current().File != "" {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-hup:
+			w.reload("SIGHUP")
+		case <-tick:
+			if w.modified() {
+				w.reload("change of " + w.Current().File)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Stop stops the Watcher and waits for Run to return
+func (w *Watcher) Stop() {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// reload reloads the Config and logs the outcome
+func (w *Watcher) reload(reason string) {
+	changes, err := w.Reload()
+	if err != nil {
+		log.Printf("config: rejected reload on %s: %v", reason, err)
+		return
+	}
+	if len(changes) == 0 {
+		log.Printf("config: reloaded on %s, no changes", reason)
+		return
+	}
+	for _, c := range changes {
+		log.Printf("config: reloaded on %s, %s", reason, c)
+	}
+}
+
+// Reload loads the Config, keeps the settings only applied on
+// restart and applies it, the current Config is kept if it is
+// invalid or cannot be applied. It returns the applied changes.
+func (w *Watcher) Reload() ([]Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// the file is not reloaded again until it is modified
+	// again, even if the reload is rejected
+	w.modTime = modTime(w.current.File)
+
+	next, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, c := range Diff(w.current, next) {
+		if restart[c.Setting] {
+			log.Printf("config: %s is only applied on restart, ignored", c)
+			continue
+		}
+		changes = append(changes, c)
+	}
+	keep(next, w.current)
+
+	if err := w.apply(next); err != nil {
+		return nil, err
+	}
+	w.current = next
+	return changes, nil
+}
+
+// modified returns whether the file of the
+// Config was modified since the last reload
+func (w *Watcher) modified() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	t := modTime(w.current.File)
+	return !t.IsZero() && !t.Equal(w.modTime)
+}
+
+// keep sets the settings of next only applied on restart to the ones of current
+func keep(next, current *Config) {
+	currentFields := fields(current)
+	for i, f := range fields(next) {
+		if restart[f.flag] {
+			reflect.ValueOf(f.value).Elem().Set(reflect.ValueOf(currentFields[i].value).Elem())
+		}
+	}
+}
+
+// modTime returns the modification time of the
+// file, zero if there is no file or it is missing
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiff checks the changed settings are listed by their flags
+func TestDiff(t *testing.T) {
+	old, new := Default(), Default()
+	new.Provider.Timeout = 2 * time.Second
+	new.Strategies.KalmanThreshold = 1.5
+
+	changes := Diff(&old, &new)
+	assert.Equal(t, []Change{
+		{Setting: "provider-timeout", Old: 500 * time.Millisecond, New: 2 * time.Second},
+		{Setting: "kalman-threshold", Old: 2.0, New: 1.5},
+	}, changes)
+	assert.Equal(t, "provider-timeout: 500ms -> 2s", changes[0].String())
+	assert.Empty(t, Diff(&old, &old))
+}
+
+// TestWatcherReload checks a reload applies the
+// changes which do not need a restart
+// Scenario:
+// 	- the file changes the addr, the timeout and the default strategy
+// 	- the file then sets an invalid threshold
+// 	- the file then sets the window, which the service fails to apply
+//
+// Expect:
+// 	- the timeout and strategy are applied, the addr is kept
+// 	- the invalid and unapplied configs are rejected and the last applied config is kept
+func TestWatcherReload(t *testing.T) {
+	path, cleanup := writeConfig(t, "xe.yaml", "strategy: trend")
+	defer cleanup()
+	reload := func() (*Config, error) {
+		return load(nil, map[string]string{EnvConfig: path})
+	}
+	current, err := reload()
+	assert.NoError(t, err)
+
+	var applied []*Config
+	var applyErr error
+	w, err := NewWatcher(current, reload, func(c *Config) error {
+		if applyErr != nil {
+			return applyErr
+		}
+		applied = append(applied, c)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`
+addr: ":8080"
+provider:
+  timeout: 2s
+strategy: kalman
+`), 0644))
+	changes, err := w.Reload()
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Setting: "provider-timeout", Old: 500 * time.Millisecond, New: 2 * time.Second},
+		{Setting: "strategy", Old: "trend", New: "kalman"},
+	}, changes)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, DefaultAddr, w.Current().Addr)
+	assert.Equal(t, "kalman", w.Current().Strategy)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("strategies: {kalman_threshold: -1}"), 0644))
+	_, err = w.Reload()
+	assert.Error(t, err)
+
+	applyErr = errors.New("invalid experiment")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("window: 14"), 0644))
+	_, err = w.Reload()
+	assert.Error(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, 2*time.Second, w.Current().Provider.Timeout)
+	assert.Equal(t, 7, w.Current().Window)
+
+	_, err = NewWatcher(current, reload, nil)
+	assert.Error(t, err)
+}
+
+// TestWatcherRun checks the Config is reloaded
+// on SIGHUP and when its file is modified
+func TestWatcherRun(t *testing.T) {
+	path, cleanup := writeConfig(t, "xe.yaml", "watch_every: 10ms")
+	defer cleanup()
+	reload := func() (*Config, error) {
+		return load(nil, map[string]string{EnvConfig: path})
+	}
+	current, err := reload()
+	assert.NoError(t, err)
+
+	applied := make(chan *Config, 2)
+	w, err := NewWatcher(current, reload, func(c *Config) error {
+		applied <- c
+		return nil
+	})
+	assert.NoError(t, err)
+
+	hup := make(chan os.Signal, 1)
+	go w.Run(hup)
+	defer w.Stop()
+
+	hup <- syscall.SIGHUP
+	assert.Equal(t, 7, (<-applied).Window)
+
+	later := time.Now().Add(time.Second)
+	assert.NoError(t, ioutil.WriteFile(path, []byte("watch_every: 10ms\nwindow: 14"), 0644))
+	assert.NoError(t, os.Chtimes(path, later, later))
+	select {
+	case c := <-applied:
+		assert.Equal(t, 14, c.Window)
+	case <-time.After(time.Second):
+		t.Fatal("config not reloaded on change of the file")
+	}
+}
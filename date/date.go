@@ -7,10 +7,9 @@ const (
 )
 
 // GenerateStartAndEnd generates the start and end date
-// in ISO string format given the number of days
-// Note: end date is always the current date
-func GenerateStartAndEnd(days int) (string, string) {
-	end := time.Now()
+// in ISO string format given the end date and the number
+// of days to look back from it
+func GenerateStartAndEnd(end time.Time, days int) (string, string) {
 	start := end.AddDate(0, 0, -days)
 
 	endDate := end.Format(layoutISO)
@@ -18,3 +17,36 @@ func GenerateStartAndEnd(days int) (string, string) {
 
 	return startDate, endDate
 }
+
+// Parse parses a date string in ISO format, i.e. YYYY-MM-DD
+func Parse(s string) (time.Time, error) {
+	return time.Parse(layoutISO, s)
+}
+
+// Format formats a time in ISO format, i.e. YYYY-MM-DD
+func Format(t time.Time) string {
+	return t.Format(layoutISO)
+}
+
+// DaysSpanning returns the number of days to look back
+// from a date so the window spans at least n business
+// days, with an allowance of a tenth for holidays
+func DaysSpanning(n int) int {
+	n += n / 10
+
+	days := 0
+	for minBusinessDays(days+1) < n {
+		days++
+	}
+	return days
+}
+
+// minBusinessDays returns the min number of business
+// days in any span of the consecutive days
+func minBusinessDays(days int) int {
+	b := days / 7 * 5
+	if r := days % 7; r > 2 {
+		b += r - 2
+	}
+	return b
+}
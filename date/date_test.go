@@ -9,7 +9,7 @@ import (
 
 func TestGenerateStartAndEnd(t *testing.T) {
 	days := 7
-	startDate, endDate := GenerateStartAndEnd(days)
+	startDate, endDate := GenerateStartAndEnd(time.Now(), days)
 	start, err := time.Parse(layoutISO, startDate)
 	assert.NoError(t, err)
 
@@ -19,3 +19,56 @@ func TestGenerateStartAndEnd(t *testing.T) {
 	daysDiff := end.Sub(start).Hours() / 24
 	assert.Equal(t, days, int(daysDiff), "Days difference is wrong")
 }
+
+// TestGenerateStartAndEndFromPastDate checks the window
+// is shifted relative to the given end date
+// Scenario:
+// 	- given an end date in the past
+//
+// Expect:
+// 	- end date is the given date and start date is 7 days before
+func TestGenerateStartAndEndFromPastDate(t *testing.T) {
+	end, err := Parse("2019-11-22")
+	assert.NoError(t, err)
+
+	startDate, endDate := GenerateStartAndEnd(end, 7)
+	assert.Equal(t, "2019-11-15", startDate, "start date is wrong")
+	assert.Equal(t, "2019-11-22", endDate, "end date is wrong")
+}
+
+// TestDaysSpanning checks the window spans the business days
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- the min number of days spanning the business days in the worst case
+func TestDaysSpanning(t *testing.T) {
+	type testParams struct {
+		description  string
+		businessDays int
+		expDays      int
+	}
+
+	cases := []testParams{
+		{description: "no business day", businessDays: 0, expDays: 0},
+		{description: "a business day, e.g. looking back from a Sunday", businessDays: 1, expDays: 2},
+		{description: "a week", businessDays: 5, expDays: 6},
+		{description: "a week and a weekend", businessDays: 6, expDays: 9},
+		{description: "four weeks and holidays", businessDays: 21, expDays: 32},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expDays, DaysSpanning(tt.businessDays))
+		})
+	}
+}
+
+// TestParse checks that only ISO dates are accepted
+func TestParse(t *testing.T) {
+	_, err := Parse("2019-11-22")
+	assert.NoError(t, err)
+
+	_, err = Parse("22/11/2019")
+	assert.Error(t, err)
+}
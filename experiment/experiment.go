@@ -0,0 +1,145 @@
+package experiment
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"sync"
+)
+
+// DefaultHeader is the default header
+// identifying the callers, i.e. their API key
+const DefaultHeader = "X-API-Key"
+
+// Arm is a group of callers served
+// recommendations by the same strategy
+type Arm struct {
+	Name     string `json:"name"`
+	Strategy string `json:"strategy"`
+
+	// Weight is the share of callers assigned
+	// to the arm relative to the other arms
+	Weight int `json:"weight"`
+}
+
+// Experiment assigns callers to arms
+// using different strategies
+type Experiment struct {
+	Name string `json:"name"`
+
+	// Header is the request header identifying the caller,
+	// callers without it are not in the experiment
+	Header string `json:"header"`
+
+	// Salt is the secret the caller IDs are hashed with
+	Salt string `json:"salt"`
+
+	Arms []Arm `json:"arms"`
+}
+
+var (
+	saltOnce    sync.Once
+	randomSalt  string
+	randomError error
+)
+
+// Load decodes the JSON experiment, the header defaults to
+// DefaultHeader and the salt to a random one, which is the
+// same for every experiment loaded until restart
+func Load(r io.Reader) (*Experiment, error) {
+	e := &Experiment{}
+	if err := json.NewDecoder(r).Decode(e); err != nil {
+		return nil, err
+	}
+	if e.Header == "" {
+		e.Header = DefaultHeader
+	}
+	if e.Salt == "" {
+		saltOnce.Do(func() {
+			b := make([]byte, 32)
+			_, randomError = rand.Read(b)
+			randomSalt = hex.EncodeToString(b)
+		})
+		if randomError != nil {
+			return nil, fmt.Errorf("generating salt: %v", randomError)
+		}
+		e.Salt = randomSalt
+	}
+	return e, nil
+}
+
+// Validate checks the experiment has a name and arms with
+// unique names, positive weights and known strategies. The
+// total weight must fit the 32 bit hash the callers are
+// assigned with.
+func (e *Experiment) Validate(known func(strategy string) bool) error {
+	if e.Name == "" || e.Header == "" {
+		return fmt.Errorf("experiment must have a name and a header")
+	}
+	if len(e.Arms) == 0 {
+		return fmt.Errorf("experiment %s must have arms", e.Name)
+	}
+
+	names := map[string]bool{}
+	var total uint64
+	for _, a := range e.Arms {
+		if a.Name == "" || names[a.Name] {
+			return fmt.Errorf("arm names must be unique and not empty, got %q", a.Name)
+		}
+		names[a.Name] = true
+
+		if a.Weight < 1 {
+			return fmt.Errorf("weight of arm %s must be positive, got %d", a.Name, a.Weight)
+		}
+		if total += uint64(a.Weight); total > math.MaxUint32 {
+			return fmt.Errorf("total weight of experiment %s must be at most %d", e.Name, uint64(math.MaxUint32))
+		}
+		if !known(a.Strategy) {
+			return fmt.Errorf("unknown strategy of arm %s: %s", a.Name, a.Strategy)
+		}
+	}
+	return nil
+}
+
+// Assign assigns the caller to an arm. The caller is hashed
+// with the name of the experiment into a bucket of the total
+// weight, so a caller is always assigned the same arm, and
+// callers are assigned independently across experiments.
+// The experiment must be valid.
+func (e *Experiment) Assign(caller string) Arm {
+	var total uint32
+	for _, a := range e.Arms {
+		total += uint32(a.Weight)
+	}
+
+	bucket := hash(e.Name+":"+caller) % total
+	for _, a := range e.Arms {
+		if bucket < uint32(a.Weight) {
+			return a
+		}
+		bucket -= uint32(a.Weight)
+	}
+	return e.Arms[len(e.Arms)-1]
+}
+
+// CallerID returns the HMAC-SHA256 of the caller keyed with
+// the salt, cut to 64 bits, which can be logged without
+// leaking its API key
+func (e *Experiment) CallerID(caller string) string {
+	mac := hmac.New(sha256.New, []byte(e.Salt))
+	mac.Write([]byte(caller))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// hash returns the 32 bit FNV-1a hash of s
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
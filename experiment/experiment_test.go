@@ -0,0 +1,139 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func known(strategy string) bool {
+	return strategy == "trend" || strategy == "kalman"
+}
+
+// TestLoad checks the experiment is decoded
+// and the header defaults to the API key
+func TestLoad(t *testing.T) {
+	e, err := Load(strings.NewReader(`{
+		"name": "trend-vs-kalman",
+		"arms": [
+			{"name": "control", "strategy": "trend", "weight": 1},
+			{"name": "kalman", "strategy": "kalman", "weight": 1}
+		]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "trend-vs-kalman", e.Name)
+	assert.Equal(t, DefaultHeader, e.Header)
+	assert.Equal(t, Arm{Name: "kalman", Strategy: "kalman", Weight: 1}, e.Arms[1])
+	assert.NoError(t, e.Validate(known))
+
+	_, err = Load(strings.NewReader(`{"name": `))
+	assert.Error(t, err)
+}
+
+// TestValidate checks invalid experiments are rejected
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- error is returned
+func TestValidate(t *testing.T) {
+	type testParams struct {
+		description string
+		experiment  Experiment
+	}
+
+	cases := []testParams{
+		{
+			description: "no name",
+			experiment:  Experiment{Header: DefaultHeader, Arms: []Arm{{Name: "a", Strategy: "trend", Weight: 1}}},
+		},
+		{
+			description: "no arms",
+			experiment:  Experiment{Name: "e", Header: DefaultHeader},
+		},
+		{
+			description: "duplicate arms",
+			experiment: Experiment{Name: "e", Header: DefaultHeader, Arms: []Arm{
+				{Name: "a", Strategy: "trend", Weight: 1},
+				{Name: "a", Strategy: "kalman", Weight: 1},
+			}},
+		},
+		{
+			description: "zero weight",
+			experiment:  Experiment{Name: "e", Header: DefaultHeader, Arms: []Arm{{Name: "a", Strategy: "trend"}}},
+		},
+		{
+			description: "total weight above the 32 bit hash",
+			experiment: Experiment{Name: "e", Header: DefaultHeader, Arms: []Arm{
+				{Name: "a", Strategy: "trend", Weight: math.MaxInt32},
+				{Name: "b", Strategy: "trend", Weight: math.MaxInt32},
+				{Name: "c", Strategy: "kalman", Weight: math.MaxInt32},
+			}},
+		},
+		{
+			description: "unknown strategy",
+			experiment: Experiment{Name: "e", Header: DefaultHeader, Arms: []Arm{
+				{Name: "a", Strategy: "astrology", Weight: 1},
+			}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Error(t, tt.experiment.Validate(known))
+		})
+	}
+}
+
+// TestAssign checks callers are assigned
+// stickily in proportion to the weights
+// Scenario:
+// 	- arms weighted 3 to 1
+// 	- 10000 callers
+//
+// Expect:
+// 	- the same caller is always assigned the same arm
+// 	- about 3 in 4 callers are assigned the first arm
+func TestAssign(t *testing.T) {
+	e := &Experiment{Name: "e", Header: DefaultHeader, Arms: []Arm{
+		{Name: "control", Strategy: "trend", Weight: 3},
+		{Name: "kalman", Strategy: "kalman", Weight: 1},
+	}}
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		caller := fmt.Sprintf("key-%d", i)
+		arm := e.Assign(caller)
+		assert.Equal(t, arm, e.Assign(caller), "assignment is not sticky")
+		counts[arm.Name]++
+	}
+	assert.InDelta(t, 7500, counts["control"], 200)
+	assert.InDelta(t, 2500, counts["kalman"], 200)
+}
+
+// TestCallerID checks the caller is hashed with the salt
+// Scenario:
+// 	- 2 experiments with a salt and 2 loaded without
+//
+// Expect:
+// 	- the same caller has the same ID with the same salt
+// 	- the ID differs across callers and salts
+// 	- experiments loaded without a salt share a random one
+func TestCallerID(t *testing.T) {
+	e := &Experiment{Salt: "secret"}
+	assert.Equal(t, e.CallerID("key-1"), e.CallerID("key-1"))
+	assert.NotEqual(t, e.CallerID("key-1"), e.CallerID("key-2"))
+	assert.NotEqual(t, e.CallerID("key-1"), (&Experiment{Salt: "other"}).CallerID("key-1"))
+	assert.Len(t, e.CallerID("key-1"), 16)
+
+	loaded, err := Load(strings.NewReader(`{"name": "a"}`))
+	assert.NoError(t, err)
+	reloaded, err := Load(strings.NewReader(`{"name": "b"}`))
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Salt, 64)
+	assert.Equal(t, loaded.Salt, reloaded.Salt)
+	assert.Equal(t, loaded.CallerID("key-1"), reloaded.CallerID("key-1"))
+}
@@ -3,17 +3,14 @@ module github.com/jeffreyyong/xe
 go 1.12
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gin-gonic/gin v1.4.0
 	github.com/go-resty/resty/v2 v2.1.0
-	github.com/golang/mock v1.3.1
-	github.com/kr/pretty v0.1.0 // indirect
+	github.com/golang/mock v1.4.4
+	github.com/prometheus/client_golang v1.12.2
+	github.com/prometheus/client_model v0.2.0
+	github.com/prometheus/common v0.32.1
 	github.com/stretchr/testify v1.4.0
-	golang.org/x/exp v0.0.0-20191030013958-a1ab85dbe136 // indirect
-	golang.org/x/net v0.0.0-20191119073136-fc4aabc6c914 // indirect
-	golang.org/x/sys v0.0.0-20191120155948-bd437916bb0e // indirect
 	gonum.org/v1/gonum v0.6.1
 	gonum.org/v1/netlib v0.0.0-20191031114514-eccb95939662 // indirect
-	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
-	gopkg.in/yaml.v2 v2.2.7 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -1,5 +1,14 @@
 package model
 
 const (
-	ConvertEndpoint = "/convert"
+	ConvertEndpoint     = "/convert"
+	HistoryEndpoint     = "/history"
+	StrategiesEndpoint  = "/strategies"
+	PerformanceEndpoint = "/strategies/:name/performance"
+	ForecastEndpoint    = "/forecast"
+	VolatilityEndpoint  = "/volatility"
+	SimulateEndpoint    = "/simulate"
+	HealthEndpoint      = "/healthz"
+	ReadyEndpoint       = "/readyz"
+	MetricsEndpoint     = "/metrics"
 )
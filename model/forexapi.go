@@ -47,3 +47,14 @@ type Rates map[string]float64
 // 	 }
 // },
 type RatesList map[string]Rates
+
+// RatePoint is the rate of a currency on a date
+// e.g.
+// {
+//   "date": "2019-11-22",
+//   "rate": 1.163061177
+// }
+type RatePoint struct {
+	Date string  `json:"date"`
+	Rate float64 `json:"rate"`
+}
@@ -1,16 +1,214 @@
 package model
 
 const (
-	ErrDecodeParams  = "invalid query parameter - currency must be provided"
-	ErrConvert       = "error converting currency"
-	ErrRouteNotFound = "route not found"
+	ErrDecodeParams    = "invalid query parameter - currency must be provided"
+	ErrConvert         = "error converting currency"
+	ErrRouteNotFound   = "route not found"
+	ErrInvalidDate     = "invalid query parameter - date must be YYYY-MM-DD and not in the future"
+	ErrInvalidWindow   = "invalid query parameter - window must be a positive number of days within the max window"
+	ErrUnknownStrategy = "invalid query parameter - unknown strategy"
+	ErrInvalidRates    = "error computing recommendation - invalid historical rates"
+
+	ErrDecodeHistoryParams = "invalid query parameter - from must be provided and to must be EUR"
+	ErrInvalidDateRange    = "invalid query parameter - start and end must be YYYY-MM-DD, end must not be in the future and start must be at most the max window before end"
+	ErrInvalidInterval     = "invalid query parameter - interval must be one of daily, weekly, monthly"
+	ErrInvalidPage         = "invalid query parameter - page and page_size must be positive integers, page_size at most 1000"
+	ErrHistory             = "error getting rate history"
+
+	ErrInvalidForecastMethod = "invalid query parameter - method must be one of holt, ar"
+	ErrInvalidForecastDays   = "invalid query parameter - days must be a positive number of business days, at most 30"
+	ErrForecastData          = "insufficient data - too few rates in the window to forecast"
+	ErrForecast              = "error forecasting rates"
+	ErrForecastRates         = "error forecasting rates - invalid historical rates"
+
+	ErrInvalidConfidence = "invalid query parameter - confidence must be between 0 and 1"
+	ErrVolatilityData    = "insufficient data - too few rates in the window to measure volatility"
+	ErrVolatility        = "error measuring volatility"
+
+	ErrInvalidSimulationMethod = "invalid query parameter - method must be one of gbm, bootstrap"
+	ErrInvalidSimulationParams = "invalid query parameter - days must be at most 30, paths at most 10000 and seed an integer"
+	ErrSimulationData          = "insufficient data - too few rates in the window to simulate"
+	ErrSimulation              = "error simulating rates"
+
+	ErrStrategyNotFound = "strategy not found"
+	ErrTrackingDisabled = "outcome tracking is disabled"
+	ErrPerformance      = "error reporting strategy performance"
+
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
 )
 
 // ConvertResp is the response struct for XE Service
 type ConvertResp struct {
-	From           string  `json:"from,omitempty"`
-	To             string  `json:"to,omitempty"`
-	Rate           float64 `json:"rate,omitempty"`
-	Recommendation string  `json:"recommendation,omitempty"`
-	Error          string  `json:"error,omitempty"`
+	From           string    `json:"from,omitempty"`
+	To             string    `json:"to,omitempty"`
+	Rate           float64   `json:"rate,omitempty"`
+	Recommendation string    `json:"recommendation,omitempty"`
+	Strength       string    `json:"strength,omitempty"`
+	Date           string    `json:"date,omitempty"`
+	Analysis       *Analysis `json:"analysis,omitempty"`
+	Votes          []Vote    `json:"votes,omitempty"`
+	Damped         bool      `json:"damped,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Analysis holds the statistics of the trend line
+// of the rates the recommendation is based on
+type Analysis struct {
+	Slope         float64 `json:"slope"`
+	Intercept     float64 `json:"intercept"`
+	RSquared      float64 `json:"r_squared"`
+	StdErr        float64 `json:"std_err"`
+	PValue        float64 `json:"p_value"`
+	Points        int     `json:"points"`
+	Gaps          int     `json:"gaps"`
+	PercentChange float64 `json:"percent_change"`
+}
+
+// Vote is the recommendation of a strategy
+// voting in an ensemble
+type Vote struct {
+	Strategy       string  `json:"strategy"`
+	Recommendation string  `json:"recommendation"`
+	Weight         float64 `json:"weight"`
+}
+
+// HistoryResp is the response struct for the
+// /history endpoint of XE Service
+type HistoryResp struct {
+	From     string      `json:"from,omitempty"`
+	To       string      `json:"to,omitempty"`
+	Start    string      `json:"start,omitempty"`
+	End      string      `json:"end,omitempty"`
+	Interval string      `json:"interval,omitempty"`
+	Page     int         `json:"page,omitempty"`
+	PageSize int         `json:"page_size,omitempty"`
+	Total    int         `json:"total,omitempty"`
+	Rates    []RatePoint `json:"rates,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// StrategiesResp is the response struct for the
+// /strategies endpoint of XE Service
+type StrategiesResp struct {
+	Default    string         `json:"default"`
+	Strategies []StrategyResp `json:"strategies"`
+}
+
+// StrategyResp describes a recommendation strategy
+// and the parameters it is tuned with
+type StrategyResp struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Params      map[string]interface{} `json:"params"`
+}
+
+// PerformanceResp is the response struct for the
+// /strategies/:name/performance endpoint of XE Service
+type PerformanceResp struct {
+	Strategy    string       `json:"strategy,omitempty"`
+	Performance *Performance `json:"performance,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// Performance is the accuracy of the recommendations
+// of a strategy scored horizon days after they were served
+type Performance struct {
+	HorizonDays     int     `json:"horizon_days"`
+	Recommendations int     `json:"recommendations"`
+	Neutral         int     `json:"neutral"`
+	Pending         int     `json:"pending"`
+	Scored          int     `json:"scored"`
+	Hits            int     `json:"hits"`
+	Misses          int     `json:"misses"`
+	Accuracy        float64 `json:"accuracy"`
+	AverageGain     float64 `json:"average_gain"`
+}
+
+// HealthResp is the response struct for the
+// /healthz and /readyz endpoints of XE Service
+type HealthResp struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of a readiness check
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ForecastResp is the response struct for the
+// /forecast endpoint of XE Service
+type ForecastResp struct {
+	From     string       `json:"from,omitempty"`
+	To       string       `json:"to,omitempty"`
+	Method   string       `json:"method,omitempty"`
+	Date     string       `json:"date,omitempty"`
+	Rate     float64      `json:"rate,omitempty"`
+	Forecast []Prediction `json:"forecast,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// Prediction is the forecast rate of a date
+// and its prediction interval
+type Prediction struct {
+	Date  string  `json:"date"`
+	Rate  float64 `json:"rate"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// VolatilityResp is the response struct for the
+// /volatility endpoint of XE Service
+type VolatilityResp struct {
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	Risk  *Risk  `json:"risk,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Risk holds the volatility and risk metrics
+// of the rates over the window
+type Risk struct {
+	Returns              int     `json:"returns"`
+	Volatility           float64 `json:"volatility"`
+	AnnualisedVolatility float64 `json:"annualised_volatility"`
+	MaxDrawdown          float64 `json:"max_drawdown"`
+	ValueAtRisk          float64 `json:"value_at_risk"`
+	Confidence           float64 `json:"confidence"`
+}
+
+// SimulationResp is the response struct for the
+// /simulate endpoint of XE Service
+type SimulationResp struct {
+	From       string      `json:"from,omitempty"`
+	To         string      `json:"to,omitempty"`
+	Method     string      `json:"method,omitempty"`
+	Date       string      `json:"date,omitempty"`
+	Rate       float64     `json:"rate,omitempty"`
+	Days       int         `json:"days,omitempty"`
+	Paths      int         `json:"paths,omitempty"`
+	Simulation *Simulation `json:"simulation,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Simulation holds the distribution of
+// the simulated rates
+type Simulation struct {
+	Drift                   float64    `json:"drift"`
+	Volatility              float64    `json:"volatility"`
+	ProbabilityBetter       float64    `json:"probability_better"`
+	ProbabilityBetterWithin float64    `json:"probability_better_within"`
+	ProbabilityWorse        float64    `json:"probability_worse"`
+	Quantiles               []Quantile `json:"quantiles"`
+}
+
+// Quantile is the rate the simulated rate
+// is at or below with probability Q
+type Quantile struct {
+	Q    float64 `json:"q"`
+	Rate float64 `json:"rate"`
 }
@@ -44,7 +44,7 @@ lint() {
 
 local_run() {
     export GO111MODULE=on
-    go run xe.go
+    go run .
 }
 
 test_all() {
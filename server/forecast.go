@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	ParamMethod = "method"
+	ParamDays   = "days"
+
+	// MaxForecastDays is the max number of
+	// business days callers can forecast
+	MaxForecastDays = 30
+)
+
+var errOutOfRange = errors.New("query param out of range")
+
+// Forecast is the handler func for /forecast endpoint
+func (h *Handler) Forecast(ctx *gin.Context) {
+	httpStatus, forecastResp, err := h.load().forecast(ctx)
+	if err != nil {
+		log.Print(err)
+	}
+	ctx.JSON(httpStatus, forecastResp)
+}
+
+func (h *Handler) forecast(ctx *gin.Context) (int, *model.ForecastResp, error) {
+	currency := ctx.Query(ParamCurrency)
+	if currency == "" {
+		return http.StatusBadRequest, &model.ForecastResp{Error: model.ErrDecodeParams}, nil
+	}
+
+	asOf, _, err := parseDate(ctx.Query(ParamDate))
+	if err != nil {
+		return http.StatusBadRequest, &model.ForecastResp{Error: model.ErrInvalidDate}, nil
+	}
+
+	window, err := h.parseWindow(ctx.Query(ParamWindow))
+	if err != nil {
+		return http.StatusBadRequest, &model.ForecastResp{Error: model.ErrInvalidWindow}, nil
+	}
+
+	method, err := calculator.ParseForecastMethod(ctx.Query(ParamMethod))
+	if err != nil {
+		return http.StatusBadRequest, &model.ForecastResp{Error: model.ErrInvalidForecastMethod}, nil
+	}
+	forecaster, ok := h.forecasters[method]
+	if !ok {
+		return http.StatusBadRequest, &model.ForecastResp{Error: model.ErrInvalidForecastMethod}, nil
+	}
+	if ctx.Query(ParamWindow) == "" {
+		window = h.forecastWindow(forecaster)
+	}
+
+	days, err := parseBounded(ctx.Query(ParamDays), calculator.DefaultForecastDays, MaxForecastDays)
+	if err != nil {
+		return http.StatusBadRequest, &model.ForecastResp{Error: model.ErrInvalidForecastDays}, nil
+	}
+
+	historicalRates, err := h.historicalRates(currency, asOf, window)
+	if err != nil {
+		return http.StatusInternalServerError, &model.ForecastResp{Error: model.ErrForecast}, err
+	}
+
+	series, err := calculator.ValidSeries(historicalRates.RatesList, calculator.EUR)
+	if err != nil {
+		return http.StatusInternalServerError, &model.ForecastResp{Error: model.ErrForecastRates}, err
+	}
+
+	predictions, err := forecaster.Forecast(series, days)
+	if err == calculator.ErrInsufficientData {
+		return http.StatusUnprocessableEntity, &model.ForecastResp{Error: model.ErrForecastData}, nil
+	}
+	if err != nil {
+		return http.StatusInternalServerError, &model.ForecastResp{Error: model.ErrForecast}, err
+	}
+
+	latest := series[len(series)-1]
+	forecastResp := &model.ForecastResp{
+		From:     currency,
+		To:       calculator.EUR,
+		Method:   string(method),
+		Date:     latest.Date,
+		Rate:     latest.Rate,
+		Forecast: toPredictions(predictions),
+	}
+	return http.StatusOK, forecastResp, nil
+}
+
+// parseBounded parses an optional positive integer query param.
+// It returns def if not provided and errors if it is not
+// between 1 and max.
+func parseBounded(s string, def, max int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 || n > max {
+		return 0, errOutOfRange
+	}
+	return n, nil
+}
+
+// toPredictions converts the predictions
+// of the forecaster to the response model
+func toPredictions(predictions []calculator.Prediction) []model.Prediction {
+	resp := make([]model.Prediction, len(predictions))
+	for i, p := range predictions {
+		resp[i] = model.Prediction{
+			Date:  p.Date,
+			Rate:  p.Rate,
+			Lower: p.Lower,
+			Upper: p.Upper,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/client"
+	forexmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestForecastParamsInvalid validates against invalid query params
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestForecastParamsInvalid(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description string
+		query       string
+		expJSON     string
+	}
+
+	cases := []testParams{
+		{
+			description: "currency is missing",
+			query:       "method=holt",
+			expJSON:     `{"error":"invalid query parameter - currency must be provided"}`,
+		},
+		{
+			description: "unknown method",
+			query:       "currency=USD&method=arima",
+			expJSON:     `{"error":"invalid query parameter - method must be one of holt, ar"}`,
+		},
+		{
+			description: "too many days",
+			query:       "currency=USD&days=60",
+			expJSON:     `{"error":"invalid query parameter - days must be a positive number of business days, at most 30"}`,
+		},
+		{
+			description: "window out of range",
+			query:       "currency=USD&window=0",
+			expJSON:     `{"error":"invalid query parameter - window must be a positive number of days within the max window"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			forecastResp := &model.ForecastResp{}
+			url := "http://localhost:3000/forecast?" + tt.query
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, forecastResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
+
+// TestForecastNoError shows the happy path
+// Scenario:
+// 	- mockFX returns the historical rates of the window ending on the date
+// 	- Holt's method forecasts 3 business days
+//
+// Expect:
+// 	- latest rate and the predictions after the weekend are returned
+// 	- StatusCode of 200 is returned
+func TestForecastNoError(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-01": model.Rates{"EUR": 1.10},
+			"2019-11-02": model.Rates{"EUR": 1.12},
+			"2019-11-03": model.Rates{"EUR": 1.11},
+			"2019-11-04": model.Rates{"EUR": 1.13},
+			"2019-11-05": model.Rates{"EUR": 1.15},
+			"2019-11-06": model.Rates{"EUR": 1.14},
+			"2019-11-07": model.Rates{"EUR": 1.16},
+		},
+		Base:      "USD",
+		StartDate: "2019-11-01",
+		EndDate:   "2019-11-07",
+	}
+
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-01", "2019-11-07").
+		Return(mockHistoricalRates, nil)
+
+	forecastResp := &model.ForecastResp{}
+	url := "http://localhost:3000/forecast?currency=USD&method=holt&days=3&window=6&date=2019-11-07"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, forecastResp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "USD", forecastResp.From)
+	assert.Equal(t, "EUR", forecastResp.To)
+	assert.Equal(t, "holt", forecastResp.Method)
+	assert.Equal(t, "2019-11-07", forecastResp.Date)
+	assert.Equal(t, 1.16, forecastResp.Rate)
+
+	expPredictions := []model.Prediction{
+		{Date: "2019-11-08", Rate: 1.1810718343749997, Lower: 1.1407309598104147, Upper: 1.2214127089395848},
+		{Date: "2019-11-11", Rate: 1.1966216999999997, Lower: 1.149576560203583, Upper: 1.2436668397964163},
+		{Date: "2019-11-12", Rate: 1.2121715656249998, Lower: 1.15730204405632, Upper: 1.2670410871936797},
+	}
+	assert.Len(t, forecastResp.Forecast, len(expPredictions))
+	for i, p := range expPredictions {
+		assert.Equal(t, p.Date, forecastResp.Forecast[i].Date, "date is wrong")
+		assert.InDelta(t, p.Rate, forecastResp.Forecast[i].Rate, 1e-9, "rate is wrong")
+		assert.InDelta(t, p.Lower, forecastResp.Forecast[i].Lower, 1e-9, "lower bound is wrong")
+		assert.InDelta(t, p.Upper, forecastResp.Forecast[i].Upper, 1e-9, "upper bound is wrong")
+	}
+}
+
+// TestForecastDefaultWindow checks the default window
+// is widened to span the rates the forecaster needs
+// Scenario:
+// 	- the handler has the built-in forecasters
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- the historical rates of the window are requested
+// 	- the rates are forecast
+func TestForecastDefaultWindow(t *testing.T) {
+	type testParams struct {
+		description string
+		query       string
+		expStart    string
+	}
+
+	cases := []testParams{
+		{
+			description: "holt needs fewer rates than the default window",
+			query:       "&method=holt",
+			expStart:    "2019-10-31",
+		},
+		{
+			description: "ar needs 2 * order + 2 rates",
+			query:       "&method=ar",
+			expStart:    "2019-10-29",
+		},
+		{
+			description: "window picked by the caller",
+			query:       "&method=ar&window=9",
+			expStart:    "2019-10-29",
+		},
+	}
+
+	historicalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-10-29": model.Rates{"EUR": 1.10},
+			"2019-10-30": model.Rates{"EUR": 1.12},
+			"2019-10-31": model.Rates{"EUR": 1.11},
+			"2019-11-01": model.Rates{"EUR": 1.13},
+			"2019-11-04": model.Rates{"EUR": 1.15},
+			"2019-11-05": model.Rates{"EUR": 1.14},
+			"2019-11-06": model.Rates{"EUR": 1.16},
+			"2019-11-07": model.Rates{"EUR": 1.15},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockFX := forexmock.NewMockForex(ctrl)
+
+			forecasters, err := calculator.NewForecasters(calculator.DefaultStrategyParams())
+			assert.NoError(t, err)
+			router := SetupAPIHandler(NewHandler(mockFX, calculator.NewRegistry(), forecasters, nil, nil,
+				DefaultSettings(), nil))
+
+			mockFX.EXPECT().GetHistoricalRates("USD", tt.expStart, "2019-11-07").
+				Return(historicalRates, nil)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/forecast?currency=USD&date=2019-11-07"+tt.query, nil)
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
+// TestForecastInsufficientData checks the window
+// must have enough rates for the method
+// Scenario:
+// 	- mockFX returns 3 rates
+// 	- AR method needs at least 6 rates
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 422 is returned
+func TestForecastInsufficientData(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-05": model.Rates{"EUR": 1.15},
+			"2019-11-06": model.Rates{"EUR": 1.14},
+			"2019-11-07": model.Rates{"EUR": 1.16},
+		},
+	}
+
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(mockHistoricalRates, nil)
+
+	forecastResp := &model.ForecastResp{}
+	url := "http://localhost:3000/forecast?currency=USD&method=ar"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, forecastResp)
+
+	expJSON := `{"error":"insufficient data - too few rates in the window to forecast"}`
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestForecastInvalidRates checks no forecast is made
+// of invalid rates
+// Scenario:
+// 	- mockFX returns a rate which is not a number
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 500 is returned
+func TestForecastInvalidRates(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-05": model.Rates{"EUR": 1.15},
+			"2019-11-06": model.Rates{"EUR": math.NaN()},
+			"2019-11-07": model.Rates{"EUR": 1.16},
+		},
+	}
+
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(mockHistoricalRates, nil)
+
+	forecastResp := &model.ForecastResp{}
+	url := "http://localhost:3000/forecast?currency=USD&method=holt"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, forecastResp)
+
+	expJSON := `{"error":"error forecasting rates - invalid historical rates"}`
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestForecastGetHistoricalRatesError checks if error is
+// returned when fx.GetHistoricalRates returns error
+// Scenario:
+// 	- mockFX is configured to return error for GetHistoricalRates
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 500 is returned
+func TestForecastGetHistoricalRatesError(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error getting historical rate"))
+
+	forecastResp := &model.ForecastResp{}
+	url := "http://localhost:3000/forecast?currency=USD"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, forecastResp)
+
+	expJSON := `{"error":"error forecasting rates"}`
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
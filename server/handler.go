@@ -3,6 +3,9 @@ package server
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"log"
 
@@ -10,47 +13,154 @@ import (
 	"github.com/jeffreyyong/xe/calculator"
 	"github.com/jeffreyyong/xe/client"
 	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/experiment"
 	"github.com/jeffreyyong/xe/model"
+	"github.com/jeffreyyong/xe/tracking"
 )
 
 const (
 	ParamCurrency = "currency"
+	ParamDate     = "date"
+	ParamWindow   = "window"
+	ParamStrategy = "strategy"
 
-	// Number of days before the current date for historical rates
+	// HeaderExperimentArm is the response header with the
+	// experiment and arm which served the recommendation
+	HeaderExperimentArm = "X-Experiment-Arm"
+
+	// Default number of days before the current date for historical rates
 	DaysForRates = 7
+
+	// Default max number of days callers can look back for historical rates
+	MaxDaysForRates = 365
 )
 
+var errNoHistoricalRates = errors.New("no historical rates")
+
 var noRouteFoundFunc = func(c *gin.Context) {
 	c.JSON(http.StatusNotFound, &model.ConvertResp{Error: model.ErrRouteNotFound})
 }
 
-// Handler that has forex client
-// and calculator engine
+// Handler that has forex client, calculator
+// strategies, forecasters, the store of the
+// last signals, the outcome tracker, settings
+// and metrics
 type Handler struct {
-	fx client.Forex
-	ce calculator.Engine
+	fx          client.Forex
+	strategies  *calculator.Registry
+	forecasters calculator.Forecasters
+	signals     calculator.SignalStore
+	tracker     *tracking.Tracker
+	settings    Settings
+	metrics     *Metrics
+
+	// current is the Handler serving the requests,
+	// a copy of the Handler is stored on Reload
+	current *atomic.Value
 }
 
-// NewHandler initialises a Handler
-func NewHandler(forex client.Forex, calculator calculator.Engine) *Handler {
-	return &Handler{
-		fx: forex,
-		ce: calculator,
+// NewHandler initialises a Handler, the recommendations
+// are damped by hysteresis across days if signals is not nil,
+// their outcomes are tracked if tracker is not nil and the
+// requests are recorded and exposed on /metrics if m is not nil
+func NewHandler(forex client.Forex, strategies *calculator.Registry, forecasters calculator.Forecasters,
+	signals calculator.SignalStore, tracker *tracking.Tracker, settings Settings, m *Metrics) *Handler {
+	h := &Handler{
+		fx:          forex,
+		strategies:  strategies,
+		forecasters: forecasters,
+		signals:     signals,
+		tracker:     tracker,
+		settings:    settings,
+		metrics:     m,
+		current:     &atomic.Value{},
 	}
+	h.current.Store(h)
+	return h
+}
+
+// Reload atomically replaces the forex client, strategies,
+// forecasters and settings at once, requests in flight finish
+// with the ones they started with
+func (h *Handler) Reload(forex client.Forex, strategies *calculator.Registry, forecasters calculator.Forecasters,
+	settings Settings) {
+	next := *h.load()
+	next.fx = forex
+	next.strategies = strategies
+	next.forecasters = forecasters
+	next.settings = settings
+	h.current.Store(&next)
+}
+
+// load returns the Handler serving the requests
+func (h *Handler) load() *Handler {
+	return h.current.Load().(*Handler)
+}
+
+// Forex returns a Forex client calling the forex client
+// the Handler serves the requests with when it's called,
+// so it follows the forex client across reloads
+func (h *Handler) Forex() client.Forex {
+	return currentForex{h}
+}
+
+// currentForex is the Forex client of the
+// Handler serving the requests
+type currentForex struct {
+	h *Handler
+}
+
+// GetLatestRate gets the latest rate from `currency` to EUR
+func (f currentForex) GetLatestRate(currency string) (*model.LatestRate, error) {
+	return f.h.load().fx.GetLatestRate(currency)
+}
+
+// GetHistoricalRate gets the rate from `currency`
+// to EUR published on the date
+func (f currentForex) GetHistoricalRate(currency string, date string) (*model.LatestRate, error) {
+	return f.h.load().fx.GetHistoricalRate(currency, date)
+}
+
+// GetHistoricalRates gets the historical rates
+// from `currency` to EUR of the period
+func (f currentForex) GetHistoricalRates(currency string, startDate string, endDate string) (*model.HistoricalRates, error) {
+	return f.h.load().fx.GetHistoricalRates(currency, startDate, endDate)
 }
 
 // SetupAPIHandler sets up a GIN router
-// with /convert GET endpoint
-func SetupAPIHandler(h *Handler) *gin.Engine {
+// with /convert, /history, /strategies,
+// /strategies/:name/performance, /forecast,
+// /volatility and /simulate GET endpoints,
+// /healthz and /readyz running the checks,
+// and /metrics if the Handler has metrics
+func SetupAPIHandler(h *Handler, checks ...Check) *gin.Engine {
 	r := gin.Default()
-	r.NoRoute(noRouteFoundFunc)
-	r.GET(model.ConvertEndpoint, h.Convert)
+	m := h.metrics
+	r.NoRoute(m.instrument(RouteNotFound), noRouteFoundFunc)
+
+	// get registers the handler of the route, recording its
+	// requests under the route rather than the path requested
+	get := func(route string, handler gin.HandlerFunc) {
+		r.GET(route, m.instrument(route), handler)
+	}
+	get(model.ConvertEndpoint, h.Convert)
+	get(model.HistoryEndpoint, h.History)
+	get(model.StrategiesEndpoint, h.Strategies)
+	get(model.PerformanceEndpoint, h.Performance)
+	get(model.ForecastEndpoint, h.Forecast)
+	get(model.VolatilityEndpoint, h.Volatility)
+	get(model.SimulateEndpoint, h.Simulate)
+	get(model.HealthEndpoint, healthz)
+	get(model.ReadyEndpoint, readyz(checks))
+	if m != nil {
+		get(model.MetricsEndpoint, gin.WrapH(m.handler))
+	}
 	return r
 }
 
 // Convert is the handler func for /convert endpoint
 func (h *Handler) Convert(ctx *gin.Context) {
-	httpStatus, forexResp, err := h.convert(ctx)
+	httpStatus, forexResp, err := h.load().convert(ctx)
 	if err != nil {
 		log.Print(err)
 	}
@@ -63,44 +173,297 @@ func (h *Handler) convert(ctx *gin.Context) (int, *model.ConvertResp, error) {
 		return http.StatusBadRequest, &model.ConvertResp{Error: model.ErrDecodeParams}, nil
 	}
 
-	// get latest rate
-	latestRate, err := h.fx.GetLatestRate(currency)
+	// point in time conversion if date is provided
+	asOf, pointInTime, err := parseDate(ctx.Query(ParamDate))
+	if err != nil {
+		return http.StatusBadRequest, &model.ConvertResp{Error: model.ErrInvalidDate}, nil
+	}
+
+	// lookback window for historical rates
+	window, err := h.parseWindow(ctx.Query(ParamWindow))
+	if err != nil {
+		return http.StatusBadRequest, &model.ConvertResp{Error: model.ErrInvalidWindow}, nil
+	}
+
+	// strategy for the recommendation, callers in the
+	// experiment are served the strategy of their arm
+	strategy, arm, ok := h.pickStrategy(ctx)
+	if !ok {
+		return http.StatusBadRequest, &model.ConvertResp{Error: model.ErrUnknownStrategy}, nil
+	}
+	if ctx.Query(ParamWindow) == "" {
+		window = h.strategyWindow(strategy)
+	}
+
+	// get latest rate, or the rate of the given date
+	rate, err := h.getRate(currency, asOf, pointInTime)
 	if err != nil {
 		return http.StatusInternalServerError, &model.ConvertResp{Error: model.ErrConvert}, err
 	}
 
 	// extract the rate
-	targetRate, err := extractTargetRate(latestRate)
+	targetRate, err := extractTargetRate(rate)
 	if err != nil {
 		return http.StatusInternalServerError, &model.ConvertResp{Error: model.ErrConvert}, err
 	}
 
 	// compute the recommendation
-	recommendation, err := h.computeRecommendation(currency)
+	recommendation, err := h.computeRecommendation(strategy.Engine, currency, asOf, window)
+	if _, ok := err.(*calculator.SeriesError); ok {
+		return http.StatusInternalServerError, &model.ConvertResp{Error: model.ErrInvalidRates}, err
+	}
 	if err != nil {
 		return http.StatusInternalServerError, &model.ConvertResp{Error: model.ErrConvert}, err
 	}
 
+	// damp the recommendation by the signal of the day before
+	// the rate, point in time conversions neither read nor save
+	// the signal
+	damped := false
+	if !pointInTime {
+		key := signalKey(currency, strategy.Name, window)
+		recommendation, damped, err = h.hysteresis(key, rate.Date, recommendation)
+		if err != nil {
+			return http.StatusInternalServerError, &model.ConvertResp{Error: model.ErrConvert}, err
+		}
+		h.track(currency, strategy.Name, window, recommendation.Signal, rate.Date, targetRate)
+	}
+
 	convertResp := &model.ConvertResp{
 		From:           currency,
 		To:             calculator.EUR,
 		Rate:           targetRate,
-		Recommendation: string(recommendation),
+		Recommendation: string(recommendation.Signal),
+		Strength:       string(recommendation.Strength),
+		Analysis:       toAnalysis(recommendation.Analysis),
+		Votes:          toVotes(recommendation.Votes),
+		Damped:         damped,
+	}
+	if pointInTime {
+		convertResp.Date = rate.Date
+	}
+	h.metrics.signal(strategy.Name, recommendation.Signal)
+	if arm != nil {
+		h.logArm(ctx, arm, convertResp)
 	}
 	return http.StatusOK, convertResp, nil
 }
 
+// getRate gets the latest rate, or the rate published
+// on asOf if it's a point in time conversion
+func (h *Handler) getRate(currency string, asOf time.Time, pointInTime bool) (*model.LatestRate, error) {
+	if pointInTime {
+		return h.fx.GetHistoricalRate(currency, date.Format(asOf))
+	}
+	return h.fx.GetLatestRate(currency)
+}
+
 // computeRecommendation
-// 1. generates a start and end date
-// 2. gets the HistoricalRates
-// 3. computes the recommendation
-func (h *Handler) computeRecommendation(currency string) (calculator.Signal, error) {
-	startDate, endDate := date.GenerateStartAndEnd(DaysForRates)
+// 1. gets the HistoricalRates of the window ending on asOf
+// 2. computes the recommendation with the engine,
+//    too few rates gives SignalInsufficientData
+func (h *Handler) computeRecommendation(ce calculator.Engine, currency string, asOf time.Time, window int) (calculator.Recommendation, error) {
+	historicalRates, err := h.historicalRates(currency, asOf, window)
+	if err != nil {
+		return calculator.Recommendation{}, err
+	}
+
+	recommendation, err := ce.Recommend(historicalRates.RatesList)
+	if err == calculator.ErrInsufficientData {
+		return calculator.Recommendation{Signal: calculator.SignalInsufficientData}, nil
+	}
+	return recommendation, err
+}
+
+// hysteresis damps the recommendation by the signal carried
+// into the day under the key and saves the signal it emits as
+// the signal of the day, so the signal advances at most once a
+// day however often it is requested. Recommendations with
+// insufficient data are neither damped nor saved.
+func (h *Handler) hysteresis(key, day string, r calculator.Recommendation) (calculator.Recommendation, bool, error) {
+	if h.signals == nil || r.Signal == calculator.SignalInsufficientData {
+		return r, false, nil
+	}
+
+	damped, isDamped := r, false
+	err := h.signals.Update(key, day, func(previous calculator.Signal) calculator.Signal {
+		damped, isDamped = calculator.Hysteresis(previous, r)
+		return damped.Signal
+	})
+	if err != nil {
+		return calculator.Recommendation{}, false, err
+	}
+	return damped, isDamped, nil
+}
+
+// track records the recommendation served so its outcome
+// is scored later, failing to record it is only logged as
+// the recommendation is served regardless
+func (h *Handler) track(currency, strategy string, window int, signal calculator.Signal, day string, rate float64) {
+	if h.tracker == nil {
+		return
+	}
+	if err := h.tracker.Record(currency, strategy, window, signal, day, rate); err != nil {
+		log.Print("recording outcome: ", err)
+	}
+}
+
+// signalKey is the key the last signal of the strategy
+// for the currency is saved under, signals over different
+// windows are kept apart as they can disagree
+func signalKey(currency, strategy string, window int) string {
+	return currency + "/" + calculator.EUR + "/" + strategy + "/" + strconv.Itoa(window)
+}
+
+// historicalRates generates a start and end date relative
+// to asOf spanning the window and gets the HistoricalRates
+func (h *Handler) historicalRates(currency string, asOf time.Time, window int) (*model.HistoricalRates, error) {
+	startDate, endDate := date.GenerateStartAndEnd(asOf, window)
 	historicalRates, err := h.fx.GetHistoricalRates(currency, startDate, endDate)
-	if err != nil || historicalRates == nil {
-		return "", err
+	if err != nil {
+		return nil, err
+	}
+	if historicalRates == nil {
+		return nil, errNoHistoricalRates
+	}
+	return historicalRates, nil
+}
+
+// toAnalysis converts the statistics of the
+// engine to the response model
+func toAnalysis(a *calculator.Analysis) *model.Analysis {
+	if a == nil {
+		return nil
+	}
+
+	return &model.Analysis{
+		Slope:         a.Slope,
+		Intercept:     a.Intercept,
+		RSquared:      a.RSquared,
+		StdErr:        a.StdErr,
+		PValue:        a.PValue,
+		Points:        a.Points,
+		Gaps:          a.Gaps,
+		PercentChange: a.PercentChange,
+	}
+}
+
+// toVotes converts the votes of an ensemble
+// engine to the response model
+func toVotes(votes []calculator.Vote) []model.Vote {
+	if len(votes) == 0 {
+		return nil
+	}
+
+	resp := make([]model.Vote, len(votes))
+	for i, v := range votes {
+		resp[i] = model.Vote{
+			Strategy:       v.Strategy,
+			Recommendation: string(v.Signal),
+			Weight:         v.Weight,
+		}
+	}
+	return resp
+}
+
+// parseDate parses the optional date query param.
+// It returns the current time if the date is not provided
+// and errors if the date is malformed or in the future.
+func parseDate(s string) (time.Time, bool, error) {
+	now := time.Now()
+	if s == "" {
+		return now, false, nil
+	}
+
+	d, err := date.Parse(s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if d.After(now) {
+		return time.Time{}, false, errors.New("date is in the future")
+	}
+	return d, true, nil
+}
+
+// strategy returns the strategy registered under the name,
+// or the default strategy if the name is empty
+func (h *Handler) strategy(name string) (calculator.Strategy, bool) {
+	if name == "" {
+		name = h.settings.Strategy
+	}
+	return h.strategies.Get(name)
+}
+
+// pickStrategy returns the strategy picked by the strategy query
+// param or, if not provided, the strategy of the arm the caller is
+// assigned to if it is in the experiment, or the default strategy
+func (h *Handler) pickStrategy(ctx *gin.Context) (calculator.Strategy, *experiment.Arm, bool) {
+	name := ctx.Query(ParamStrategy)
+	e := h.settings.Experiment
+	if name != "" || e == nil {
+		strategy, ok := h.strategy(name)
+		return strategy, nil, ok
+	}
+
+	caller := ctx.GetHeader(e.Header)
+	if caller == "" {
+		strategy, ok := h.strategy("")
+		return strategy, nil, ok
+	}
+
+	arm := e.Assign(caller)
+	strategy, ok := h.strategy(arm.Strategy)
+	return strategy, &arm, ok
+}
+
+// logArm logs which arm of the experiment served the
+// response and sets the arm in the response header
+func (h *Handler) logArm(ctx *gin.Context, arm *experiment.Arm, convertResp *model.ConvertResp) {
+	e := h.settings.Experiment
+	ctx.Header(HeaderExperimentArm, e.Name+"/"+arm.Name)
+	log.Printf("experiment %s: arm %s served %s with strategy %s to caller %s",
+		e.Name, arm.Name, convertResp.Recommendation, arm.Strategy, e.CallerID(ctx.GetHeader(e.Header)))
+}
+
+// parseWindow parses the optional window query param.
+// It returns the default window if not provided and errors
+// if the window is not between 1 and the max window.
+func (h *Handler) parseWindow(s string) (int, error) {
+	if s == "" {
+		return h.settings.Window, nil
+	}
+
+	window, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if window < 1 || window > h.settings.MaxWindow {
+		return 0, errors.New("window out of range")
+	}
+	return window, nil
+}
+
+// strategyWindow returns the default window widened to span
+// the rates the strategy needs, up to the max window
+func (h *Handler) strategyWindow(strategy calculator.Strategy) int {
+	window := strategy.Window(h.settings.Window)
+	if window > h.settings.MaxWindow {
+		return h.settings.MaxWindow
+	}
+	return window
+}
+
+// forecastWindow returns the default window widened to span
+// the rates the forecaster needs, up to the max window
+func (h *Handler) forecastWindow(forecaster calculator.Forecaster) int {
+	window := h.settings.Window
+	if min := date.DaysSpanning(forecaster.MinRates()); min > window {
+		window = min
+	}
+	if window > h.settings.MaxWindow {
+		return h.settings.MaxWindow
 	}
-	return h.ce.Recommend(historicalRates.RatesList), nil
+	return window
 }
 
 func extractTargetRate(l *model.LatestRate) (float64, error) {
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/model"
+)
+
+// Check is a readiness check of a dependency of the
+// service, Check returns an error if it is unavailable
+type Check struct {
+	Name  string
+	Check func() error
+}
+
+// CachedCheck returns a check returning the outcome of check
+// cached for ttl, so the dependency is not called on every probe
+func CachedCheck(check func() error, ttl time.Duration) func() error {
+	var mu sync.Mutex
+	var checkedAt time.Time
+	var last error
+
+	return func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !checkedAt.IsZero() && time.Since(checkedAt) < ttl {
+			return last
+		}
+		last, checkedAt = check(), time.Now()
+		return last
+	}
+}
+
+// healthz is the handler func for /healthz endpoint,
+// the service is alive as long as it serves the request
+func healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, &model.HealthResp{Status: model.StatusOK})
+}
+
+// readyz returns the handler func for /readyz endpoint, the
+// service is ready to serve requests if every check passes
+func readyz(checks []Check) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		errs := make([]error, len(checks))
+		var wg sync.WaitGroup
+		for i, c := range checks {
+			wg.Add(1)
+			go func(i int, c Check) {
+				defer wg.Done()
+				errs[i] = c.Check()
+			}(i, c)
+		}
+		wg.Wait()
+
+		httpStatus := http.StatusOK
+		healthResp := &model.HealthResp{Status: model.StatusOK, Checks: map[string]model.CheckResult{}}
+		for i, c := range checks {
+			if errs[i] != nil {
+				httpStatus = http.StatusServiceUnavailable
+				healthResp.Status = model.StatusUnavailable
+				healthResp.Checks[c.Name] = model.CheckResult{Status: model.StatusUnavailable, Error: errs[i].Error()}
+				continue
+			}
+			healthResp.Checks[c.Name] = model.CheckResult{Status: model.StatusOK}
+		}
+		ctx.JSON(httpStatus, healthResp)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHealth checks /healthz and /readyz
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- the status and the breakdown of the checks
+func TestHealth(t *testing.T) {
+	type testParams struct {
+		description   string
+		endpoint      string
+		checks        []Check
+		expStatusCode int
+		expJSON       string
+	}
+
+	ok := func() error { return nil }
+	open := func() error { return errors.New("circuit breaker open") }
+
+	cases := []testParams{
+		{
+			description:   "alive",
+			endpoint:      "/healthz",
+			checks:        []Check{{Name: "provider", Check: open}},
+			expStatusCode: http.StatusOK,
+			expJSON:       `{"status":"ok"}`,
+		},
+		{
+			description:   "ready without checks",
+			endpoint:      "/readyz",
+			expStatusCode: http.StatusOK,
+			expJSON:       `{"status":"ok"}`,
+		},
+		{
+			description:   "ready",
+			endpoint:      "/readyz",
+			checks:        []Check{{Name: "provider", Check: ok}, {Name: "outcome-store", Check: ok}},
+			expStatusCode: http.StatusOK,
+			expJSON:       `{"status":"ok","checks":{"outcome-store":{"status":"ok"},"provider":{"status":"ok"}}}`,
+		},
+		{
+			description:   "a check fails",
+			endpoint:      "/readyz",
+			checks:        []Check{{Name: "circuit-breaker", Check: open}, {Name: "outcome-store", Check: ok}},
+			expStatusCode: http.StatusServiceUnavailable,
+			expJSON: `{"status":"unavailable","checks":{"circuit-breaker":` +
+				`{"status":"unavailable","error":"circuit breaker open"},"outcome-store":{"status":"ok"}}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			router := SetupAPIHandler(NewHandler(nil, nil, nil, nil, nil, DefaultSettings(), nil), tt.checks...)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.endpoint, nil))
+
+			assert.Equal(t, tt.expStatusCode, rec.Code)
+			assert.Equal(t, tt.expJSON, rec.Body.String())
+		})
+	}
+}
+
+// TestCachedCheck checks the outcome of the
+// check is cached for the ttl
+func TestCachedCheck(t *testing.T) {
+	calls := 0
+	check := func() error {
+		calls++
+		return errors.New("provider unreachable")
+	}
+
+	cached := CachedCheck(check, time.Hour)
+	assert.Error(t, cached())
+	assert.Error(t, cached())
+	assert.Equal(t, 1, calls)
+
+	uncached := CachedCheck(check, 0)
+	assert.Error(t, uncached())
+	assert.Error(t, uncached())
+	assert.Equal(t, 3, calls)
+}
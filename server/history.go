@@ -0,0 +1,160 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	ParamFrom     = "from"
+	ParamTo       = "to"
+	ParamStart    = "start"
+	ParamEnd      = "end"
+	ParamInterval = "interval"
+	ParamPage     = "page"
+	ParamPageSize = "page_size"
+
+	// DefaultPageSize is the number of rates per page
+	// when page_size is not provided
+	DefaultPageSize = 100
+
+	// MaxPageSize is the max number of rates per page
+	MaxPageSize = 1000
+)
+
+var (
+	errInvalidDateRange = errors.New("start date is after end date")
+	errDateRangeTooLong = errors.New("date range is longer than the max window")
+	errInvalidPage      = errors.New("page out of range")
+)
+
+// History is the handler func for /history endpoint
+func (h *Handler) History(ctx *gin.Context) {
+	httpStatus, historyResp, err := h.load().history(ctx)
+	if err != nil {
+		log.Print(err)
+	}
+	ctx.JSON(httpStatus, historyResp)
+}
+
+func (h *Handler) history(ctx *gin.Context) (int, *model.HistoryResp, error) {
+	from := ctx.Query(ParamFrom)
+	to := ctx.DefaultQuery(ParamTo, calculator.EUR)
+	if from == "" || to != calculator.EUR {
+		return http.StatusBadRequest, &model.HistoryResp{Error: model.ErrDecodeHistoryParams}, nil
+	}
+
+	start, end, err := h.parseDateRange(ctx.Query(ParamStart), ctx.Query(ParamEnd))
+	if err != nil {
+		return http.StatusBadRequest, &model.HistoryResp{Error: model.ErrInvalidDateRange}, nil
+	}
+
+	interval, err := calculator.ParseInterval(ctx.Query(ParamInterval))
+	if err != nil {
+		return http.StatusBadRequest, &model.HistoryResp{Error: model.ErrInvalidInterval}, nil
+	}
+
+	page, pageSize, err := parsePage(ctx.Query(ParamPage), ctx.Query(ParamPageSize))
+	if err != nil {
+		return http.StatusBadRequest, &model.HistoryResp{Error: model.ErrInvalidPage}, nil
+	}
+
+	historicalRates, err := h.fx.GetHistoricalRates(from, start, end)
+	if err != nil || historicalRates == nil {
+		return http.StatusInternalServerError, &model.HistoryResp{Error: model.ErrHistory}, err
+	}
+
+	series, err := calculator.Resample(calculator.Series(historicalRates.RatesList, to), interval)
+	if err != nil {
+		return http.StatusInternalServerError, &model.HistoryResp{Error: model.ErrHistory}, err
+	}
+
+	historyResp := &model.HistoryResp{
+		From:     from,
+		To:       to,
+		Start:    start,
+		End:      end,
+		Interval: string(interval),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    len(series),
+		Rates:    paginate(series, page, pageSize),
+	}
+	return http.StatusOK, historyResp, nil
+}
+
+// parseDateRange validates the start and end query params.
+// End defaults to today and must not be in the future, start
+// defaults to the window days before end and must be at most
+// the max window days before it, so a request can't make the
+// api fetch years of rates.
+func (h *Handler) parseDateRange(startParam, endParam string) (string, string, error) {
+	end, _, err := parseDate(endParam)
+	if err != nil {
+		return "", "", err
+	}
+
+	startDate, endDate := date.GenerateStartAndEnd(end, h.settings.Window)
+	if startParam == "" {
+		return startDate, endDate, nil
+	}
+
+	start, err := date.Parse(startParam)
+	if err != nil {
+		return "", "", err
+	}
+	if start.After(end) {
+		return "", "", errInvalidDateRange
+	}
+	if start.Before(end.AddDate(0, 0, -h.settings.MaxWindow)) {
+		return "", "", errDateRangeTooLong
+	}
+	return date.Format(start), endDate, nil
+}
+
+// parsePage validates the page and page_size query params
+func parsePage(pageParam, pageSizeParam string) (int, int, error) {
+	page, pageSize := 1, DefaultPageSize
+
+	var err error
+	if pageParam != "" {
+		if page, err = strconv.Atoi(pageParam); err != nil {
+			return 0, 0, err
+		}
+	}
+	if pageSizeParam != "" {
+		if pageSize, err = strconv.Atoi(pageSizeParam); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if page < 1 || pageSize < 1 || pageSize > MaxPageSize {
+		return 0, 0, errInvalidPage
+	}
+	return page, pageSize, nil
+}
+
+// paginate returns the rates of the page,
+// pages start from 1
+func paginate(series []model.RatePoint, page, pageSize int) []model.RatePoint {
+	// compare the page to the last page before multiplying
+	// so a large page cannot overflow the start index
+	if len(series) == 0 || page-1 > (len(series)-1)/pageSize {
+		return nil
+	}
+
+	start := (page - 1) * pageSize
+
+	end := start + pageSize
+	if end > len(series) {
+		end = len(series)
+	}
+	return series[start:end]
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jeffreyyong/xe/client"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHistoryParamsInvalid validates against invalid query params
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestHistoryParamsInvalid(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description string
+		query       string
+		expJSON     string
+	}
+
+	cases := []testParams{
+		{
+			description: "from is missing",
+			query:       "to=EUR",
+			expJSON:     `{"error":"invalid query parameter - from must be provided and to must be EUR"}`,
+		},
+		{
+			description: "to is not EUR",
+			query:       "from=USD&to=GBP",
+			expJSON:     `{"error":"invalid query parameter - from must be provided and to must be EUR"}`,
+		},
+		{
+			description: "start is after end",
+			query:       "from=USD&start=2019-11-22&end=2019-11-15",
+			expJSON:     `{"error":"invalid query parameter - start and end must be YYYY-MM-DD, end must not be in the future and start must be at most the max window before end"}`,
+		},
+		{
+			description: "end is in the future",
+			query:       "from=USD&start=2019-11-15&end=2999-01-01",
+			expJSON:     `{"error":"invalid query parameter - start and end must be YYYY-MM-DD, end must not be in the future and start must be at most the max window before end"}`,
+		},
+		{
+			description: "start is more than the max window before end",
+			query:       "from=USD&start=2018-11-21&end=2019-11-22",
+			expJSON:     `{"error":"invalid query parameter - start and end must be YYYY-MM-DD, end must not be in the future and start must be at most the max window before end"}`,
+		},
+		{
+			description: "unknown interval",
+			query:       "from=USD&interval=hourly",
+			expJSON:     `{"error":"invalid query parameter - interval must be one of daily, weekly, monthly"}`,
+		},
+		{
+			description: "page size too large",
+			query:       "from=USD&page_size=5000",
+			expJSON:     `{"error":"invalid query parameter - page and page_size must be positive integers, page_size at most 1000"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			historyResp := &model.HistoryResp{}
+			url := "http://localhost:3000/history?" + tt.query
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, historyResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
+
+// TestHistoryNoError shows the happy path
+// Scenario:
+// 	- mockFX returns historical rates in random order
+// 	- weekly interval with a page size of 1 and the second page
+//
+// Expect:
+// 	- rates are sorted, resampled weekly and the second page is returned
+// 	- StatusCode of 200 is returned
+func TestHistoryNoError(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-22": model.Rates{
+				"EUR": 0.9043226623,
+			},
+			"2019-11-15": model.Rates{
+				"EUR": 0.9060433089,
+			},
+			"2019-11-18": model.Rates{
+				"EUR": 0.9031791907,
+			},
+			"2019-11-14": model.Rates{
+				"EUR": 0.9075233687,
+			},
+		},
+		Base:      "USD",
+		StartDate: "2019-11-14",
+		EndDate:   "2019-11-22",
+	}
+
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-14", "2019-11-22").
+		Return(mockHistoricalRates, nil)
+
+	historyResp := &model.HistoryResp{}
+	url := "http://localhost:3000/history?from=USD&to=EUR&start=2019-11-14&end=2019-11-22&interval=weekly&page=2&page_size=1"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, historyResp)
+
+	expJSON := `{"from":"USD","to":"EUR","start":"2019-11-14","end":"2019-11-22","interval":"weekly","page":2,"page_size":1,"total":2,"rates":[{"date":"2019-11-22","rate":0.9043226623}]}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestPaginate checks the right slice of rates is returned
+func TestPaginate(t *testing.T) {
+	series := []model.RatePoint{
+		{Date: "2019-11-20", Rate: 1},
+		{Date: "2019-11-21", Rate: 2},
+		{Date: "2019-11-22", Rate: 3},
+	}
+
+	assert.Equal(t, series[:2], paginate(series, 1, 2))
+	assert.Equal(t, series[2:], paginate(series, 2, 2))
+	assert.Nil(t, paginate(series, 3, 2))
+	assert.Nil(t, paginate(nil, 1, 2))
+
+	page, pageSize, err := parsePage("9223372036854775807", "2")
+	assert.NoError(t, err)
+	assert.Nil(t, paginate(series, page, pageSize))
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RouteNotFound is the route label of the
+// requests which did not match any route
+const RouteNotFound = "not_found"
+
+// Metrics records the requests served by
+// the router and the signals emitted
+type Metrics struct {
+	handler  http.Handler
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	signals  *prometheus.CounterVec
+}
+
+// NewMetrics registers the metrics of the server and the
+// collectors of the process and the Go runtime in r, they
+// are exposed on /metrics with the other metrics of r. It
+// panics if they are already registered.
+func NewMetrics(r *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		handler: promhttp.HandlerFor(r, promhttp.HandlerOpts{}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xe_http_requests_total",
+			Help: "Number of requests served by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "xe_http_request_duration_seconds",
+			Help:    "Latency of the requests served by route and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "code"}),
+		signals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xe_signals_total",
+			Help: "Number of signals emitted by strategy.",
+		}, []string{"strategy", "signal"}),
+	}
+	r.MustRegister(m.requests, m.duration, m.signals,
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector())
+	return m
+}
+
+// instrument returns the middleware recording the requests
+// of the route, a nil Metrics records nothing
+func (m *Metrics) instrument(route string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if m == nil {
+			ctx.Next()
+			return
+		}
+
+		start := time.Now()
+		ctx.Next()
+		code := strconv.Itoa(ctx.Writer.Status())
+		m.requests.WithLabelValues(route, ctx.Request.Method, code).Inc()
+		m.duration.WithLabelValues(route, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// signal records a signal emitted by the strategy,
+// a nil Metrics records nothing
+func (m *Metrics) signal(strategy string, signal calculator.Signal) {
+	if m == nil {
+		return
+	}
+	m.signals.WithLabelValues(strategy, string(signal)).Inc()
+}
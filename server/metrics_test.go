@@ -0,0 +1,65 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetrics checks the requests and signals are exposed on /metrics
+// Scenario:
+// 	- /convert is requested twice and recommends to convert
+// 	- /convert is requested without a currency
+// 	- a path without a route is requested
+//
+// Expect:
+// 	- the requests are counted by route, method and status code
+// 	- the latency of the requests is observed by route and status code
+// 	- the signals are counted by strategy
+// 	- the metrics of the Go runtime are exposed
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	mockCE, mockFX, xeService, ctrl := setupTestServerWith(t, testOptions{metrics: m})
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockFX.EXPECT().GetLatestRate("USD").
+		Return(&model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}, nil).Times(2)
+	mockFX.EXPECT().GetHistoricalRates("USD", gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil).Times(2)
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{Signal: calculator.SignalConvert}, nil).Times(2)
+
+	for _, path := range []string{"/convert?currency=USD", "/convert?currency=USD", "/convert", "/unknown"} {
+		resp, err := http.Get("http://localhost:3000" + path)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues(model.ConvertEndpoint, http.MethodGet, "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(model.ConvertEndpoint, http.MethodGet, "400")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(RouteNotFound, http.MethodGet, "404")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.signals.WithLabelValues(testStrategy, string(calculator.SignalConvert))))
+
+	resp, err := http.Get("http://localhost:3000" + model.MetricsEndpoint)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, string(expfmt.FmtText), resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `xe_http_requests_total{code="200",method="GET",route="/convert"} 2`)
+	assert.Contains(t, string(body), `xe_http_request_duration_seconds_count{code="200",route="/convert"} 2`)
+	assert.Contains(t, string(body), `xe_signals_total{signal="`+string(calculator.SignalConvert)+`",strategy="`+testStrategy+`"} 2`)
+	assert.Contains(t, string(body), "go_goroutines ")
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	ParamName = "name"
+)
+
+// Performance is the handler func for /strategies/:name/performance
+// endpoint, it reports the accuracy of the recommendations of the
+// strategy whose outcome is known
+func (h *Handler) Performance(ctx *gin.Context) {
+	httpStatus, performanceResp, err := h.load().performance(ctx)
+	if err != nil {
+		log.Print(err)
+	}
+	ctx.JSON(httpStatus, performanceResp)
+}
+
+func (h *Handler) performance(ctx *gin.Context) (int, *model.PerformanceResp, error) {
+	name := ctx.Param(ParamName)
+	if _, ok := h.strategies.Get(name); !ok {
+		return http.StatusNotFound, &model.PerformanceResp{Error: model.ErrStrategyNotFound}, nil
+	}
+
+	if h.tracker == nil {
+		return http.StatusServiceUnavailable, &model.PerformanceResp{Error: model.ErrTrackingDisabled}, nil
+	}
+
+	p, err := h.tracker.Performance(name)
+	if err != nil {
+		return http.StatusInternalServerError, &model.PerformanceResp{Error: model.ErrPerformance}, err
+	}
+
+	performanceResp := &model.PerformanceResp{
+		Strategy: name,
+		Performance: &model.Performance{
+			HorizonDays:     p.Horizon,
+			Recommendations: p.Recommendations,
+			Neutral:         p.Neutral,
+			Pending:         p.Pending,
+			Scored:          p.Scored,
+			Hits:            p.Hits,
+			Misses:          p.Misses,
+			Accuracy:        p.Accuracy,
+			AverageGain:     p.AverageGain,
+		},
+	}
+	return http.StatusOK, performanceResp, nil
+}
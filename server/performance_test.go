@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/client"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/jeffreyyong/xe/store"
+	"github.com/jeffreyyong/xe/tracking"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPerformanceErrors validates against unknown
+// strategies and disabled tracking
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message and StatusCode are returned
+func TestPerformanceErrors(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description   string
+		strategy      string
+		expStatusCode int
+		expJSON       string
+	}
+
+	cases := []testParams{
+		{
+			description:   "unknown strategy",
+			strategy:      "unknown",
+			expStatusCode: http.StatusNotFound,
+			expJSON:       `{"error":"strategy not found"}`,
+		},
+		{
+			description:   "tracking disabled",
+			strategy:      testStrategy,
+			expStatusCode: http.StatusServiceUnavailable,
+			expJSON:       `{"error":"outcome tracking is disabled"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			performanceResp := &model.PerformanceResp{}
+			url := "http://localhost:3000/strategies/" + tt.strategy + "/performance"
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, performanceResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.expStatusCode, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
+
+// TestPerformanceNoError shows the happy path
+// Scenario:
+// 	- a convert recommendation for USD and a don't convert one for
+// 	  GBP of 2019-11-01 at 1.0 are scored with the rate of 1.25 a week later
+// 	- a recommendation is served by /convert
+//
+// Expect:
+// 	- the served recommendation is recorded as pending
+// 	- the scored ones are a hit and a miss
+// 	- StatusCode of 200 is returned
+func TestPerformanceNoError(t *testing.T) {
+	tracker, err := tracking.NewTracker(store.NewOutcomes(store.DefaultOutcomeLimit), tracking.DefaultHorizon)
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.Record("USD", testStrategy, 7, calculator.SignalConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("GBP", testStrategy, 7, calculator.SignalNoConvert, "2019-11-01", 1))
+	_, err = tracker.ScoreDue(time.Date(2019, 11, 9, 0, 0, 0, 0, time.UTC), func(string, string) (float64, error) {
+		return 1.25, nil
+	})
+	assert.NoError(t, err)
+
+	mockCE, mockFX, xeService, ctrl := setupTestServerWith(t, testOptions{tracker: tracker})
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockFX.EXPECT().GetLatestRate(gomock.Any()).
+		Return(&model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}, nil)
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil)
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{Signal: calculator.SignalConvert}, nil)
+
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	_, err = httpClient.GET("http://localhost:3000/convert?currency=USD", &model.ConvertResp{})
+	assert.NoError(t, err)
+
+	performanceResp := &model.PerformanceResp{}
+	resp, err := httpClient.GET("http://localhost:3000/strategies/"+testStrategy+"/performance", performanceResp)
+
+	expJSON := `{"strategy":"mock","performance":{"horizon_days":7,"recommendations":3,"neutral":0,` +
+		`"pending":1,"scored":2,"hits":1,"misses":1,"accuracy":0.5,"average_gain":0}}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
@@ -2,36 +2,53 @@ package server
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"net/http"
 	"time"
 )
 
+// DefaultShutdownTimeout is the default time the
+// requests in flight are given to finish on Stop
+const DefaultShutdownTimeout = 5 * time.Second
+
 type XEService struct {
 	*http.Server
+	shutdownTimeout time.Duration
 }
 
-func NewXEService(h http.Handler, addr string) *XEService {
+// NewXEService initialises an XEService serving h on
+// addr, Stop waits up to the shutdown timeout for
+// the requests in flight to finish
+func NewXEService(h http.Handler, addr string, shutdownTimeout time.Duration) *XEService {
 	server := &http.Server{
 		Handler: h,
 		Addr:    addr,
 	}
 
 	return &XEService{
-		Server: server,
+		Server:          server,
+		shutdownTimeout: shutdownTimeout,
 	}
 }
 
-func (x *XEService) Run() {
+// Run serves until the XEService is stopped, it
+// returns an error if it fails to start or serve
+func (x *XEService) Run() error {
 	if err := x.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal("XE service failed to start up: ", err)
+		return fmt.Errorf("XE service failed to start up: %v", err)
 	}
+	return nil
 }
 
-func (x *XEService) Stop() {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// Stop stops accepting connections and waits for the
+// requests in flight to finish, the connections still
+// active after the shutdown timeout are closed
+func (x *XEService) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), x.shutdownTimeout)
 	defer cancel()
 	if err := x.Shutdown(ctx); err != nil {
-		log.Fatal("XE service failed to shut down: ", err)
+		x.Close()
+		return fmt.Errorf("XE service failed to shut down: %v", err)
 	}
+	return nil
 }
@@ -1,21 +1,31 @@
 package server
 
 import (
+	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jeffreyyong/xe/calculator"
 	calculatormock "github.com/jeffreyyong/xe/calculator/mock"
 	"github.com/jeffreyyong/xe/client"
 	forexmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/experiment"
 	"github.com/jeffreyyong/xe/model"
+	"github.com/jeffreyyong/xe/store"
+	"github.com/jeffreyyong/xe/tracking"
 	"github.com/stretchr/testify/assert"
 )
 
 const (
 	testServerAddr = "localhost:3000"
+	testStrategy   = "mock"
+	testEnsemble   = "mock-ensemble"
 )
 
 // TestQueryParamsMissing validates against missing query params
@@ -29,12 +39,12 @@ func TestQueryParamsMissing(t *testing.T) {
 	_, _, xeService, ctrl := setupTestServer(t)
 	defer ctrl.Finish()
 
-	go xeService.Run()
+	runTestServer(t, xeService)
 	defer xeService.Stop()
 
 	convertResp := &model.ConvertResp{}
 	urlNoQueryParam := "http://localhost:3000/convert"
-	httpClient := client.NewHTTPClient()
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
 	resp, err := httpClient.GET(urlNoQueryParam, convertResp)
 
 	expJSON := `{"error":"invalid query parameter - currency must be provided"}`
@@ -55,7 +65,7 @@ func TestGetLatestRateError(t *testing.T) {
 	_, mockFX, xeService, ctrl := setupTestServer(t)
 	defer ctrl.Finish()
 
-	go xeService.Run()
+	runTestServer(t, xeService)
 	defer xeService.Stop()
 
 	mockFX.EXPECT().GetLatestRate(gomock.Any()).
@@ -63,7 +73,7 @@ func TestGetLatestRateError(t *testing.T) {
 
 	convertResp := &model.ConvertResp{}
 	url := "http://localhost:3000/convert?currency=USD"
-	httpClient := client.NewHTTPClient()
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
 	resp, err := httpClient.GET(url, convertResp)
 
 	expJSON := `{"error":"error converting currency"}`
@@ -84,7 +94,7 @@ func TestExtractTargetRateError(t *testing.T) {
 	_, mockFX, xeService, ctrl := setupTestServer(t)
 	defer ctrl.Finish()
 
-	go xeService.Run()
+	runTestServer(t, xeService)
 	defer xeService.Stop()
 
 	// error with unrecognised interest rate:
@@ -102,7 +112,7 @@ func TestExtractTargetRateError(t *testing.T) {
 
 	convertResp := &model.ConvertResp{}
 	url := "http://localhost:3000/convert?currency=USD"
-	httpClient := client.NewHTTPClient()
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
 	resp, err := httpClient.GET(url, convertResp)
 
 	expJSON := `{"error":"error converting currency"}`
@@ -123,7 +133,7 @@ func TestGetHistoricalRatesError(t *testing.T) {
 	_, mockFX, xeService, ctrl := setupTestServer(t)
 	defer ctrl.Finish()
 
-	go xeService.Run()
+	runTestServer(t, xeService)
 	defer xeService.Stop()
 
 	mockLatestRate := &model.LatestRate{
@@ -142,7 +152,7 @@ func TestGetHistoricalRatesError(t *testing.T) {
 
 	convertResp := &model.ConvertResp{}
 	url := "http://localhost:3000/convert?currency=USD"
-	httpClient := client.NewHTTPClient()
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
 	resp, err := httpClient.GET(url, convertResp)
 
 	expJSON := `{"error":"error converting currency"}`
@@ -164,7 +174,7 @@ func TestHandlerConvertNoError(t *testing.T) {
 	mockCE, mockFX, xeService, ctrl := setupTestServer(t)
 	defer ctrl.Finish()
 
-	go xeService.Run()
+	runTestServer(t, xeService)
 	defer xeService.Stop()
 
 	mockLatestRate := &model.LatestRate{
@@ -195,11 +205,12 @@ func TestHandlerConvertNoError(t *testing.T) {
 	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(mockHistoricalRates, nil)
 
-	mockCE.EXPECT().Recommend(gomock.Any()).Return(calculator.SignalConvert)
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{Signal: calculator.SignalConvert}, nil)
 
 	convertResp := &model.ConvertResp{}
 	url := "http://localhost:3000/convert?currency=USD"
-	httpClient := client.NewHTTPClient()
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
 	resp, err := httpClient.GET(url, convertResp)
 
 	expJSON := `{"from":"USD","to":"EUR","rate":1.163061177,"recommendation":"convert"}`
@@ -209,15 +220,683 @@ func TestHandlerConvertNoError(t *testing.T) {
 
 }
 
+// TestHandlerConvertPointInTime checks the rate and recommendation
+// are computed as of the given date
+// Scenario:
+// 	- query param 'date' is provided
+// 	- mockFX returns the historical rate for that date
+// 	- mockFX returns the historical rates for the week before that date
+//
+// Expect:
+// 	- rate of that date is fetched rather than the latest rate
+// 	- lookback window ends on that date
+// 	- date is returned in the JSON body
+// 	- StatusCode of 200 is returned
+func TestHandlerConvertPointInTime(t *testing.T) {
+	mockCE, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockRate := &model.LatestRate{
+		Rates: model.Rates{
+			"EUR": 1.1689343994,
+		},
+		Base: "USD",
+		Date: "2019-11-21",
+	}
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-20": model.Rates{
+				"EUR": 1.1666569445,
+			},
+			"2019-11-21": model.Rates{
+				"EUR": 1.1689343994,
+			},
+		},
+		Base:      "USD",
+		StartDate: "2019-11-14",
+		EndDate:   "2019-11-21",
+	}
+
+	mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").
+		Return(mockRate, nil)
+
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-14", "2019-11-21").
+		Return(mockHistoricalRates, nil)
+
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{Signal: calculator.SignalNoConvert}, nil)
+
+	convertResp := &model.ConvertResp{}
+	url := "http://localhost:3000/convert?currency=USD&date=2019-11-21"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, convertResp)
+
+	expJSON := `{"from":"USD","to":"EUR","rate":1.1689343994,"recommendation":"don't convert","date":"2019-11-21"}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestHandlerConvertInvalidDate validates against malformed
+// and future dates
+// Scenario:
+// 	- query param 'date' is not YYYY-MM-DD or is in the future
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestHandlerConvertInvalidDate(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	future := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
+	for _, d := range []string{"21-11-2019", future} {
+		convertResp := &model.ConvertResp{}
+		url := "http://localhost:3000/convert?currency=USD&date=" + d
+		httpClient := client.NewHTTPClient(client.DefaultSettings())
+		resp, err := httpClient.GET(url, convertResp)
+
+		expJSON := `{"error":"invalid query parameter - date must be YYYY-MM-DD and not in the future"}`
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+		assert.Equal(t, expJSON, string(resp.Body()))
+	}
+}
+
+// TestHandlerConvertWindow checks the lookback window
+// for historical rates can be set by the caller
+// Scenario:
+// 	- query param 'window' of 30 days is provided with a date
+//
+// Expect:
+// 	- historical rates are fetched from 30 days before the date
+// 	- StatusCode of 200 is returned
+func TestHandlerConvertWindow(t *testing.T) {
+	mockCE, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockRate := &model.LatestRate{
+		Rates: model.Rates{
+			"EUR": 1.1689343994,
+		},
+		Base: "USD",
+		Date: "2019-11-21",
+	}
+
+	mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-21").
+		Return(mockRate, nil)
+
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-10-22", "2019-11-21").
+		Return(&model.HistoricalRates{}, nil)
+
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{}, calculator.ErrInsufficientData)
+
+	convertResp := &model.ConvertResp{}
+	url := "http://localhost:3000/convert?currency=USD&date=2019-11-21&window=30"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, convertResp)
+
+	expJSON := `{"from":"USD","to":"EUR","rate":1.1689343994,"recommendation":"insufficient data","date":"2019-11-21"}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestHandlerConvertInvalidWindow validates against windows
+// that are not numbers or out of range
+// Scenario:
+// 	- query param 'window' is not a number, zero or above the max window
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestHandlerConvertInvalidWindow(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	for _, w := range []string{"week", "0", "366"} {
+		convertResp := &model.ConvertResp{}
+		url := "http://localhost:3000/convert?currency=USD&window=" + w
+		httpClient := client.NewHTTPClient(client.DefaultSettings())
+		resp, err := httpClient.GET(url, convertResp)
+
+		expJSON := `{"error":"invalid query parameter - window must be a positive number of days within the max window"}`
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+		assert.Equal(t, expJSON, string(resp.Body()))
+	}
+}
+
+// TestHandlerConvertStrategyWindow checks the default window
+// is widened to span the rates the strategy needs
+// Scenario:
+// 	- the handler has the built-in strategies and a max window of 30 days
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- the historical rates of the window are requested
+func TestHandlerConvertStrategyWindow(t *testing.T) {
+	type testParams struct {
+		description string
+		query       string
+		expStart    string
+	}
+
+	cases := []testParams{
+		{
+			description: "trend needs fewer rates than the default window",
+			query:       "&strategy=trend",
+			expStart:    "2019-11-15",
+		},
+		{
+			description: "rsi needs 15 rates",
+			query:       "&strategy=rsi",
+			expStart:    "2019-10-30",
+		},
+		{
+			description: "ensemble is capped by the max window",
+			query:       "&strategy=ensemble",
+			expStart:    "2019-10-23",
+		},
+		{
+			description: "window picked by the caller",
+			query:       "&strategy=rsi&window=7",
+			expStart:    "2019-11-15",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockFX := forexmock.NewMockForex(ctrl)
+
+			builtIn, err := calculator.NewStrategies(calculator.DefaultStrategyParams())
+			assert.NoError(t, err)
+			settings := DefaultSettings()
+			settings.MaxWindow = 30
+			router := SetupAPIHandler(NewHandler(mockFX, builtIn, nil, nil, nil, settings, nil))
+
+			mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-22").
+				Return(&model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}, nil)
+			mockFX.EXPECT().GetHistoricalRates("USD", tt.expStart, "2019-11-22").
+				Return(&model.HistoricalRates{}, nil)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/convert?currency=USD&date=2019-11-22"+tt.query, nil)
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
+// TestHandlerConvertEnsemble checks the votes of an
+// ensemble are returned alongside the recommendation
+// Scenario:
+// 	- query param 'strategy' is an ensemble of the mock strategy
+// 	- mockCE votes for convert
+//
+// Expect:
+// 	- right JSON with the votes is provided
+// 	- StatusCode of 200 is returned
+func TestHandlerConvertEnsemble(t *testing.T) {
+	mockCE, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockLatestRate := &model.LatestRate{
+		Rates: model.Rates{
+			"EUR": 1.163061177,
+		},
+		Base: "GBP",
+		Date: "2019-11-22",
+	}
+
+	mockFX.EXPECT().GetLatestRate(gomock.Any()).
+		Return(mockLatestRate, nil)
+
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil)
+
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{Signal: calculator.SignalConvert}, nil)
+
+	convertResp := &model.ConvertResp{}
+	url := "http://localhost:3000/convert?currency=GBP&strategy=mock-ensemble"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, convertResp)
+
+	expJSON := `{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"convert","votes":[{"strategy":"mock","recommendation":"convert","weight":1}]}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestHandlerConvertAnalysis checks the strength and the statistics
+// of the trend line are returned alongside the recommendation
+// Scenario:
+// 	- mockCE returns a graded recommendation with an analysis
+//
+// Expect:
+// 	- right JSON with the analysis is provided
+// 	- StatusCode of 200 is returned
+func TestHandlerConvertAnalysis(t *testing.T) {
+	mockCE, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockLatestRate := &model.LatestRate{
+		Rates: model.Rates{
+			"EUR": 1.163061177,
+		},
+		Base: "GBP",
+		Date: "2019-11-22",
+	}
+
+	mockFX.EXPECT().GetLatestRate(gomock.Any()).
+		Return(mockLatestRate, nil)
+
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil)
+
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{
+			Signal:   calculator.SignalConvert,
+			Strength: calculator.StrengthWeak,
+			Analysis: &calculator.Analysis{
+				Slope:         -0.0009,
+				Intercept:     1.17,
+				RSquared:      0.35,
+				StdErr:        0.0006,
+				PValue:        0.21,
+				Points:        6,
+				PercentChange: -0.37,
+			},
+		}, nil)
+
+	convertResp := &model.ConvertResp{}
+	url := "http://localhost:3000/convert?currency=GBP"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, convertResp)
+
+	expJSON := `{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"convert","strength":"weak",` +
+		`"analysis":{"slope":-0.0009,"intercept":1.17,"r_squared":0.35,"std_err":0.0006,"p_value":0.21,"points":6,"gaps":0,"percent_change":-0.37}}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestHandlerConvertUnknownStrategy validates against
+// strategies that are not registered
+// Scenario:
+// 	- query param 'strategy' is not a registered strategy
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestHandlerConvertUnknownStrategy(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	convertResp := &model.ConvertResp{}
+	url := "http://localhost:3000/convert?currency=USD&strategy=unknown"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, convertResp)
+
+	expJSON := `{"error":"invalid query parameter - unknown strategy"}`
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestHandlerConvertInvalidRates checks that no recommendation
+// is given when the historical rates can't be used
+// Scenario:
+// 	- mockCE returns a SeriesError for the historical rates
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 500 is returned
+func TestHandlerConvertInvalidRates(t *testing.T) {
+	mockCE, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockLatestRate := &model.LatestRate{
+		Rates: model.Rates{
+			"EUR": 1.163061177,
+		},
+		Base: "GBP",
+		Date: "2019-11-22",
+	}
+
+	mockFX.EXPECT().GetLatestRate(gomock.Any()).
+		Return(mockLatestRate, nil)
+
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil)
+
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{}, calculator.NewSeriesError("2019-11-21", "missing EUR rate"))
+
+	convertResp := &model.ConvertResp{}
+	url := "http://localhost:3000/convert?currency=GBP"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, convertResp)
+
+	expJSON := `{"error":"error computing recommendation - invalid historical rates"}`
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestHandlerConvertHysteresis checks that the recommendation
+// does not flip between days on a weak signal
+// Scenario:
+// 	- mockCE recommends a weak 'convert' on a day, then a weak
+// 	  'don't convert' twice on the next day, then a strong
+// 	  'don't convert' on the day after
+// 	- a point in time conversion with a weak 'convert' in between
+//
+// Expect:
+// 	- 'convert' is given, then damped to 'neutral' on both requests
+// 	  of the next day, then 'don't convert'
+// 	- the point in time conversion is not damped
+func TestHandlerConvertHysteresis(t *testing.T) {
+	mockCE, mockFX, xeService, ctrl := setupTestServerWith(t, testOptions{signals: store.NewMemory()})
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	rateOn := func(day string) *model.LatestRate {
+		return &model.LatestRate{
+			Rates: model.Rates{
+				"EUR": 1.163061177,
+			},
+			Base: "GBP",
+			Date: day,
+		}
+	}
+	gomock.InOrder(
+		mockFX.EXPECT().GetLatestRate(gomock.Any()).Return(rateOn("2019-11-20"), nil),
+		mockFX.EXPECT().GetLatestRate(gomock.Any()).Return(rateOn("2019-11-21"), nil).Times(2),
+		mockFX.EXPECT().GetLatestRate(gomock.Any()).Return(rateOn("2019-11-22"), nil),
+	)
+	mockFX.EXPECT().GetHistoricalRate(gomock.Any(), gomock.Any()).
+		Return(rateOn("2019-11-22"), nil)
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil).Times(5)
+
+	gomock.InOrder(
+		mockCE.EXPECT().Recommend(gomock.Any()).
+			Return(calculator.Recommendation{Signal: calculator.SignalConvert, Strength: calculator.StrengthWeak}, nil),
+		mockCE.EXPECT().Recommend(gomock.Any()).
+			Return(calculator.Recommendation{Signal: calculator.SignalNoConvert, Strength: calculator.StrengthWeak}, nil).Times(2),
+		mockCE.EXPECT().Recommend(gomock.Any()).
+			Return(calculator.Recommendation{Signal: calculator.SignalConvert, Strength: calculator.StrengthWeak}, nil),
+		mockCE.EXPECT().Recommend(gomock.Any()).
+			Return(calculator.Recommendation{Signal: calculator.SignalNoConvert, Strength: calculator.StrengthStrong}, nil),
+	)
+
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	urls := []string{
+		"http://localhost:3000/convert?currency=GBP",
+		"http://localhost:3000/convert?currency=GBP",
+		"http://localhost:3000/convert?currency=GBP",
+		"http://localhost:3000/convert?currency=GBP&date=2019-11-22",
+		"http://localhost:3000/convert?currency=GBP",
+	}
+	expJSONs := []string{
+		`{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"convert","strength":"weak"}`,
+		`{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"neutral","damped":true}`,
+		`{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"neutral","damped":true}`,
+		`{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"convert","strength":"weak","date":"2019-11-22"}`,
+		`{"from":"GBP","to":"EUR","rate":1.163061177,"recommendation":"don't convert","strength":"strong"}`,
+	}
+	for i, url := range urls {
+		resp, err := httpClient.GET(url, &model.ConvertResp{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, expJSONs[i], string(resp.Body()))
+	}
+}
+
+// TestHandlerConvertExperiment checks callers are served
+// the strategy of the arm they are assigned to
+// Scenario:
+// 	- an experiment with an arm of the mock strategy and
+// 	  an arm of the mock ensemble
+// 	- callers with and without an API key, and a caller
+// 	  picking a strategy
+//
+// Expect:
+// 	- callers with an API key get the arm in the response header,
+// 	  the ensemble arm is served with votes
+// 	- the others are not in the experiment
+func TestHandlerConvertExperiment(t *testing.T) {
+	e := &experiment.Experiment{Name: "ab", Header: experiment.DefaultHeader, Arms: []experiment.Arm{
+		{Name: "control", Strategy: testStrategy, Weight: 1},
+		{Name: "ensemble", Strategy: testEnsemble, Weight: 1},
+	}}
+	mockCE, mockFX, xeService, ctrl := setupTestServerWith(t, testOptions{experiment: e})
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockFX.EXPECT().GetLatestRate(gomock.Any()).
+		Return(&model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}, nil).AnyTimes()
+	mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.HistoricalRates{}, nil).AnyTimes()
+	mockCE.EXPECT().Recommend(gomock.Any()).
+		Return(calculator.Recommendation{Signal: calculator.SignalConvert}, nil).AnyTimes()
+
+	// find a caller of each arm
+	callers := map[string]string{}
+	for i := 0; len(callers) < 2; i++ {
+		caller := "key-" + strconv.Itoa(i)
+		callers[e.Assign(caller).Name] = caller
+	}
+
+	type testParams struct {
+		description string
+		caller      string
+		query       string
+		expArm      string
+		expVotes    bool
+	}
+
+	cases := []testParams{
+		{
+			description: "caller of the control arm",
+			caller:      callers["control"],
+			expArm:      "ab/control",
+		},
+		{
+			description: "caller of the ensemble arm",
+			caller:      callers["ensemble"],
+			expArm:      "ab/ensemble",
+			expVotes:    true,
+		},
+		{
+			description: "caller without an API key",
+		},
+		{
+			description: "caller picking a strategy",
+			caller:      callers["ensemble"],
+			query:       "&strategy=" + testStrategy,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:3000/convert?currency=USD"+tt.query, nil)
+			assert.NoError(t, err)
+			if tt.caller != "" {
+				req.Header.Set(experiment.DefaultHeader, tt.caller)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			convertResp := &model.ConvertResp{}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(convertResp))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.expArm, resp.Header.Get(HeaderExperimentArm), "arm is wrong")
+			assert.Equal(t, tt.expVotes, len(convertResp.Votes) > 0, "strategy is wrong")
+		})
+	}
+}
+
 func setupTestServer(t *testing.T) (*calculatormock.MockEngine, *forexmock.MockForex, *XEService, *gomock.Controller) {
+	return setupTestServerWith(t, testOptions{})
+}
+
+// TestXEServiceStop checks Stop drains the requests in flight
+// Scenario:
+// 	- a request taking 100ms is in flight when the service is stopped
+// 	- the same with a shutdown timeout of 10ms
+//
+// Expect:
+// 	- the request is served and Stop returns no error
+// 	- Stop returns an error once the timeout expires and closes the connection
+func TestXEServiceStop(t *testing.T) {
+	type testParams struct {
+		description string
+		timeout     time.Duration
+		expErr      bool
+	}
+
+	cases := []testParams{
+		{
+			description: "request finishes within the timeout",
+			timeout:     time.Second,
+		},
+		{
+			description: "request outlives the timeout",
+			timeout:     10 * time.Millisecond,
+			expErr:      true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			started := make(chan struct{})
+			slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				time.Sleep(100 * time.Millisecond)
+				w.WriteHeader(http.StatusOK)
+			})
+			xeService := NewXEService(slow, testServerAddr, tt.timeout)
+			runTestServer(t, xeService)
+
+			served := make(chan error, 1)
+			go func() {
+				resp, err := http.Get("http://" + testServerAddr)
+				if err == nil {
+					resp.Body.Close()
+				}
+				served <- err
+			}()
+			<-started
+
+			err := xeService.Stop()
+			if tt.expErr {
+				assert.Error(t, err)
+				assert.Error(t, <-served)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, <-served)
+		})
+	}
+}
+
+// testOptions are the optional dependencies
+// and settings of the test server
+type testOptions struct {
+	signals    calculator.SignalStore
+	tracker    *tracking.Tracker
+	experiment *experiment.Experiment
+	metrics    *Metrics
+}
+
+func setupTestServerWith(t *testing.T, opts testOptions) (*calculatormock.MockEngine,
+	*forexmock.MockForex, *XEService, *gomock.Controller) {
 	ctrl := gomock.NewController(t)
 
 	mockFX := forexmock.NewMockForex(ctrl)
 	mockCE := calculatormock.NewMockEngine(ctrl)
 
-	h := NewHandler(mockFX, mockCE)
+	strategies := calculator.NewRegistry()
+	err := strategies.Register(calculator.Strategy{
+		Name:   testStrategy,
+		Params: calculator.Params{"threshold": 1},
+		Engine: mockCE,
+	})
+	assert.NoError(t, err)
+
+	ensemble, err := calculator.NewEnsembleEngine(calculator.VoteMajority,
+		calculator.Member{Name: testStrategy, Engine: mockCE})
+	assert.NoError(t, err)
+	err = strategies.Register(calculator.Strategy{
+		Name:   testEnsemble,
+		Params: calculator.Params{},
+		Engine: ensemble,
+	})
+	assert.NoError(t, err)
+
+	settings := DefaultSettings()
+	settings.Strategy = testStrategy
+	settings.Experiment = opts.experiment
+
+	forecasters, err := calculator.NewForecasters(calculator.DefaultStrategyParams())
+	assert.NoError(t, err)
+
+	h := NewHandler(mockFX, strategies, forecasters, opts.signals, opts.tracker, settings, opts.metrics)
 	httpHandler := SetupAPIHandler(h)
-	xeService := NewXEService(httpHandler, testServerAddr)
+	xeService := NewXEService(httpHandler, testServerAddr, DefaultShutdownTimeout)
 
 	return mockCE, mockFX, xeService, ctrl
 }
+
+// runTestServer starts the service and waits until
+// it accepts connections
+func runTestServer(t *testing.T, xeService *XEService) {
+	go xeService.Run()
+
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial("tcp", testServerAddr)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("test server failed to start")
+}
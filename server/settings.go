@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/experiment"
+)
+
+// Settings holds the operator tunables of the Handler
+type Settings struct {
+	// Window is the default number of days before the
+	// conversion date for historical rates
+	Window int
+
+	// MaxWindow is the max number of days callers can
+	// request with the window query param
+	MaxWindow int
+
+	// Strategy is the name of the strategy used
+	// when callers don't pick one
+	Strategy string
+
+	// Experiment assigns the callers who don't pick a
+	// strategy to arms using different strategies, no
+	// experiment runs if nil
+	Experiment *experiment.Experiment
+}
+
+// DefaultSettings returns the Settings the service
+// runs with when the operator sets nothing
+func DefaultSettings() Settings {
+	return Settings{
+		Window:    DaysForRates,
+		MaxWindow: MaxDaysForRates,
+		Strategy:  calculator.StrategyTrend,
+	}
+}
+
+// Validate checks the Settings are consistent
+func (s Settings) Validate() error {
+	if s.MaxWindow < 1 {
+		return fmt.Errorf("max window must be positive, got %d", s.MaxWindow)
+	}
+	if s.Window < 1 || s.Window > s.MaxWindow {
+		return fmt.Errorf("window must be between 1 and %d, got %d", s.MaxWindow, s.Window)
+	}
+	if s.Strategy == "" {
+		return fmt.Errorf("strategy must be provided")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSettingsValidate checks inconsistent settings are rejected
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- error is returned for invalid settings only
+func TestSettingsValidate(t *testing.T) {
+	type testParams struct {
+		description string
+		settings    Settings
+		expErr      bool
+	}
+
+	cases := []testParams{
+		{
+			description: "default settings are valid",
+			settings:    DefaultSettings(),
+			expErr:      false,
+		},
+		{
+			description: "window above max window",
+			settings:    Settings{Window: 30, MaxWindow: 7},
+			expErr:      true,
+		},
+		{
+			description: "zero window",
+			settings:    Settings{Window: 0, MaxWindow: 7},
+			expErr:      true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	ParamPaths = "paths"
+	ParamSeed  = "seed"
+
+	// MaxSimulationDays is the max number of
+	// business days callers can simulate
+	MaxSimulationDays = 30
+
+	// MaxSimulationPaths is the max number
+	// of paths callers can simulate
+	MaxSimulationPaths = 10000
+)
+
+// Simulate is the handler func for /simulate endpoint
+func (h *Handler) Simulate(ctx *gin.Context) {
+	httpStatus, simulationResp, err := h.load().simulate(ctx)
+	if err != nil {
+		log.Print(err)
+	}
+	ctx.JSON(httpStatus, simulationResp)
+}
+
+func (h *Handler) simulate(ctx *gin.Context) (int, *model.SimulationResp, error) {
+	currency := ctx.Query(ParamCurrency)
+	if currency == "" {
+		return http.StatusBadRequest, &model.SimulationResp{Error: model.ErrDecodeParams}, nil
+	}
+
+	asOf, _, err := parseDate(ctx.Query(ParamDate))
+	if err != nil {
+		return http.StatusBadRequest, &model.SimulationResp{Error: model.ErrInvalidDate}, nil
+	}
+
+	window, err := h.parseWindow(ctx.Query(ParamWindow))
+	if err != nil {
+		return http.StatusBadRequest, &model.SimulationResp{Error: model.ErrInvalidWindow}, nil
+	}
+
+	method, err := calculator.ParseSimulationMethod(ctx.Query(ParamMethod))
+	if err != nil {
+		return http.StatusBadRequest, &model.SimulationResp{Error: model.ErrInvalidSimulationMethod}, nil
+	}
+
+	config, err := parseSimulationConfig(method, ctx.Query(ParamDays), ctx.Query(ParamPaths), ctx.Query(ParamSeed))
+	if err != nil {
+		return http.StatusBadRequest, &model.SimulationResp{Error: model.ErrInvalidSimulationParams}, nil
+	}
+
+	historicalRates, err := h.historicalRates(currency, asOf, window)
+	if err != nil {
+		return http.StatusInternalServerError, &model.SimulationResp{Error: model.ErrSimulation}, err
+	}
+
+	simulation, err := calculator.Simulate(historicalRates.RatesList, config)
+	if err == calculator.ErrInsufficientData {
+		return http.StatusUnprocessableEntity, &model.SimulationResp{Error: model.ErrSimulationData}, nil
+	}
+	if err != nil {
+		return http.StatusInternalServerError, &model.SimulationResp{Error: model.ErrSimulation}, err
+	}
+
+	series := calculator.Series(historicalRates.RatesList, calculator.EUR)
+	latest := series[len(series)-1]
+	simulationResp := &model.SimulationResp{
+		From:       currency,
+		To:         calculator.EUR,
+		Method:     string(method),
+		Date:       latest.Date,
+		Rate:       latest.Rate,
+		Days:       config.Days,
+		Paths:      config.Paths,
+		Simulation: toSimulation(simulation),
+	}
+	return http.StatusOK, simulationResp, nil
+}
+
+// parseSimulationConfig parses the optional days, paths and
+// seed query params, they default to the defaults of the
+// simulation
+func parseSimulationConfig(method calculator.SimulationMethod, daysParam, pathsParam, seedParam string) (calculator.SimulationConfig, error) {
+	days, err := parseBounded(daysParam, calculator.DefaultSimulationDays, MaxSimulationDays)
+	if err != nil {
+		return calculator.SimulationConfig{}, err
+	}
+	paths, err := parseBounded(pathsParam, calculator.DefaultSimulationPaths, MaxSimulationPaths)
+	if err != nil {
+		return calculator.SimulationConfig{}, err
+	}
+
+	seed := int64(calculator.DefaultSimulationSeed)
+	if seedParam != "" {
+		if seed, err = strconv.ParseInt(seedParam, 10, 64); err != nil {
+			return calculator.SimulationConfig{}, err
+		}
+	}
+
+	return calculator.SimulationConfig{
+		Method: method,
+		Days:   days,
+		Paths:  paths,
+		Seed:   seed,
+	}, nil
+}
+
+// toSimulation converts the distribution of
+// the simulated rates to the response model
+func toSimulation(s *calculator.Simulation) *model.Simulation {
+	quantiles := make([]model.Quantile, len(s.Quantiles))
+	for i, q := range s.Quantiles {
+		quantiles[i] = model.Quantile{Q: q.Q, Rate: q.Rate}
+	}
+
+	return &model.Simulation{
+		Drift:                   s.Drift,
+		Volatility:              s.Volatility,
+		ProbabilityBetter:       s.ProbabilityBetter,
+		ProbabilityBetterWithin: s.ProbabilityBetterWithin,
+		ProbabilityWorse:        s.ProbabilityWorse,
+		Quantiles:               quantiles,
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/client"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSimulateParamsInvalid validates against invalid query params
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestSimulateParamsInvalid(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description string
+		query       string
+		expJSON     string
+	}
+
+	cases := []testParams{
+		{
+			description: "currency is missing",
+			query:       "method=gbm",
+			expJSON:     `{"error":"invalid query parameter - currency must be provided"}`,
+		},
+		{
+			description: "unknown method",
+			query:       "currency=USD&method=heston",
+			expJSON:     `{"error":"invalid query parameter - method must be one of gbm, bootstrap"}`,
+		},
+		{
+			description: "too many paths",
+			query:       "currency=USD&paths=1000000",
+			expJSON:     `{"error":"invalid query parameter - days must be at most 30, paths at most 10000 and seed an integer"}`,
+		},
+		{
+			description: "seed is not an integer",
+			query:       "currency=USD&seed=abc",
+			expJSON:     `{"error":"invalid query parameter - days must be at most 30, paths at most 10000 and seed an integer"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			simulationResp := &model.SimulationResp{}
+			url := "http://localhost:3000/simulate?" + tt.query
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, simulationResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
+
+// TestSimulateNoError shows the happy path
+// Scenario:
+// 	- mockFX returns rates rising by 10% every day
+// 	- bootstrap of 2 days
+//
+// Expect:
+// 	- every path is better than the latest rate
+// 	- every quantile is 2 rises of 10% above the latest rate
+// 	- StatusCode of 200 is returned
+func TestSimulateNoError(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-20": model.Rates{"EUR": 1},
+			"2019-11-21": model.Rates{"EUR": 1.1},
+			"2019-11-22": model.Rates{"EUR": 1.21},
+		},
+		Base:      "USD",
+		StartDate: "2019-11-15",
+		EndDate:   "2019-11-22",
+	}
+
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-15", "2019-11-22").
+		Return(mockHistoricalRates, nil)
+
+	simulationResp := &model.SimulationResp{}
+	url := "http://localhost:3000/simulate?currency=USD&method=bootstrap&days=2&paths=50&date=2019-11-22"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, simulationResp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "bootstrap", simulationResp.Method)
+	assert.Equal(t, "2019-11-22", simulationResp.Date)
+	assert.Equal(t, 1.21, simulationResp.Rate)
+	assert.Equal(t, 2, simulationResp.Days)
+	assert.Equal(t, 50, simulationResp.Paths)
+
+	simulation := simulationResp.Simulation
+	assert.NotNil(t, simulation)
+	assert.InDelta(t, math.Log(1.1), simulation.Drift, 1e-12, "drift is wrong")
+	assert.Equal(t, 1.0, simulation.ProbabilityBetter)
+	assert.Equal(t, 1.0, simulation.ProbabilityBetterWithin)
+	assert.Equal(t, 0.0, simulation.ProbabilityWorse)
+	assert.Len(t, simulation.Quantiles, 5)
+	for _, q := range simulation.Quantiles {
+		assert.InDelta(t, 1.4641, q.Rate, 1e-9, "quantile is wrong")
+	}
+}
+
+// TestSimulateErrors checks errors getting the rates
+// and simulating are returned
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message and StatusCode are returned
+func TestSimulateErrors(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description     string
+		historicalRates *model.HistoricalRates
+		err             error
+		expStatus       int
+		expJSON         string
+	}
+
+	cases := []testParams{
+		{
+			description: "GetHistoricalRates errors",
+			err:         errors.New("error getting historical rate"),
+			expStatus:   http.StatusInternalServerError,
+			expJSON:     `{"error":"error simulating rates"}`,
+		},
+		{
+			description: "too few rates",
+			historicalRates: &model.HistoricalRates{
+				RatesList: model.RatesList{
+					"2019-11-22": model.Rates{"EUR": 1.15},
+				},
+			},
+			expStatus: http.StatusUnprocessableEntity,
+			expJSON:   `{"error":"insufficient data - too few rates in the window to simulate"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.historicalRates, tt.err)
+
+			simulationResp := &model.SimulationResp{}
+			url := "http://localhost:3000/simulate?currency=USD"
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, simulationResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.expStatus, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
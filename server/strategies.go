@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/model"
+)
+
+// Strategies is the handler func for /strategies endpoint,
+// it lists the strategies callers can pick for /convert
+func (h *Handler) Strategies(ctx *gin.Context) {
+	h = h.load()
+	strategiesResp := &model.StrategiesResp{
+		Default:    h.settings.Strategy,
+		Strategies: []model.StrategyResp{},
+	}
+
+	for _, s := range h.strategies.List() {
+		strategiesResp.Strategies = append(strategiesResp.Strategies, model.StrategyResp{
+			Name:        s.Name,
+			Description: s.Description,
+			Params:      s.Params,
+		})
+	}
+	ctx.JSON(http.StatusOK, strategiesResp)
+}
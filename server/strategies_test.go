@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/client"
+	forexmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStrategies checks the registered strategies
+// are listed with their parameters
+// Scenario:
+// 	- the handler has the mock strategy registered as the default
+// 	- and an ensemble of the mock strategy
+//
+// Expect:
+// 	- the strategies and their parameters are listed
+// 	- StatusCode of 200 is returned
+func TestStrategies(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	strategiesResp := &model.StrategiesResp{}
+	url := "http://localhost:3000/strategies"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, strategiesResp)
+
+	expJSON := `{"default":"mock","strategies":[{"name":"mock","params":{"threshold":1}},{"name":"mock-ensemble","params":{}}]}`
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, expJSON, string(resp.Body()))
+}
+
+// TestStrategiesReload checks the reloaded strategies and
+// settings are served once the Handler is reloaded
+// Scenario:
+// 	- the handler has the built-in strategies and trend as the default
+// 	- the handler is reloaded with only kalman as the default
+//
+// Expect:
+// 	- kalman is listed as the only strategy and the default
+func TestStrategiesReload(t *testing.T) {
+	builtIn, err := calculator.NewStrategies(calculator.DefaultStrategyParams())
+	assert.NoError(t, err)
+	h := NewHandler(nil, builtIn, nil, nil, nil, DefaultSettings(), nil)
+	router := SetupAPIHandler(h)
+
+	kalman, _ := builtIn.Get(calculator.StrategyKalman)
+	strategies := calculator.NewRegistry()
+	assert.NoError(t, strategies.Register(kalman))
+	settings := DefaultSettings()
+	settings.Strategy = calculator.StrategyKalman
+	h.Reload(nil, strategies, nil, settings)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, model.StrategiesEndpoint, nil))
+	strategiesResp := &model.StrategiesResp{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), strategiesResp))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, calculator.StrategyKalman, strategiesResp.Default)
+	assert.Len(t, strategiesResp.Strategies, 1)
+	assert.Equal(t, calculator.StrategyKalman, strategiesResp.Strategies[0].Name)
+}
+
+// TestHandlerForexReload checks the forex client is
+// replaced with the strategies on Reload
+// Scenario:
+// 	- the handler is reloaded with another forex client
+//
+// Expect:
+// 	- the Forex of the handler calls the first client
+// 	  before the reload and the other one after it
+func TestHandlerForexReload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	oldFX := forexmock.NewMockForex(ctrl)
+	newFX := forexmock.NewMockForex(ctrl)
+
+	builtIn, err := calculator.NewStrategies(calculator.DefaultStrategyParams())
+	assert.NoError(t, err)
+	h := NewHandler(oldFX, builtIn, nil, nil, nil, DefaultSettings(), nil)
+	fx := h.Forex()
+
+	rate := &model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-22"}
+	oldFX.EXPECT().GetLatestRate("USD").Return(rate, nil)
+	newFX.EXPECT().GetLatestRate("USD").Return(rate, nil)
+
+	_, err = fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+	h.Reload(newFX, builtIn, nil, DefaultSettings())
+	_, err = fx.GetLatestRate("USD")
+	assert.NoError(t, err)
+}
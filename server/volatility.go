@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/date"
+	"github.com/jeffreyyong/xe/model"
+)
+
+const (
+	ParamConfidence = "confidence"
+)
+
+var errInvalidConfidence = errors.New("confidence out of range")
+
+// Volatility is the handler func for /volatility endpoint
+func (h *Handler) Volatility(ctx *gin.Context) {
+	httpStatus, volatilityResp, err := h.load().volatility(ctx)
+	if err != nil {
+		log.Print(err)
+	}
+	ctx.JSON(httpStatus, volatilityResp)
+}
+
+func (h *Handler) volatility(ctx *gin.Context) (int, *model.VolatilityResp, error) {
+	currency := ctx.Query(ParamCurrency)
+	if currency == "" {
+		return http.StatusBadRequest, &model.VolatilityResp{Error: model.ErrDecodeParams}, nil
+	}
+
+	asOf, _, err := parseDate(ctx.Query(ParamDate))
+	if err != nil {
+		return http.StatusBadRequest, &model.VolatilityResp{Error: model.ErrInvalidDate}, nil
+	}
+
+	window, err := h.parseWindow(ctx.Query(ParamWindow))
+	if err != nil {
+		return http.StatusBadRequest, &model.VolatilityResp{Error: model.ErrInvalidWindow}, nil
+	}
+
+	confidence, err := parseConfidence(ctx.Query(ParamConfidence))
+	if err != nil {
+		return http.StatusBadRequest, &model.VolatilityResp{Error: model.ErrInvalidConfidence}, nil
+	}
+
+	historicalRates, err := h.historicalRates(currency, asOf, window)
+	if err != nil {
+		return http.StatusInternalServerError, &model.VolatilityResp{Error: model.ErrVolatility}, err
+	}
+
+	risk, err := calculator.RiskMetrics(historicalRates.RatesList, confidence)
+	if err == calculator.ErrInsufficientData {
+		return http.StatusUnprocessableEntity, &model.VolatilityResp{Error: model.ErrVolatilityData}, nil
+	}
+	if err != nil {
+		return http.StatusInternalServerError, &model.VolatilityResp{Error: model.ErrVolatility}, err
+	}
+
+	startDate, endDate := date.GenerateStartAndEnd(asOf, window)
+	volatilityResp := &model.VolatilityResp{
+		From:  currency,
+		To:    calculator.EUR,
+		Start: startDate,
+		End:   endDate,
+		Risk: &model.Risk{
+			Returns:              risk.Returns,
+			Volatility:           risk.Volatility,
+			AnnualisedVolatility: risk.AnnualisedVolatility,
+			MaxDrawdown:          risk.MaxDrawdown,
+			ValueAtRisk:          risk.ValueAtRisk,
+			Confidence:           risk.Confidence,
+		},
+	}
+	return http.StatusOK, volatilityResp, nil
+}
+
+// parseConfidence parses the optional confidence query param.
+// It returns the default confidence if not provided and errors
+// if the confidence is not between 0 and 1.
+func parseConfidence(s string) (float64, error) {
+	if s == "" {
+		return calculator.DefaultVaRConfidence, nil
+	}
+
+	confidence, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !(confidence > 0 && confidence < 1) {
+		return 0, errInvalidConfidence
+	}
+	return confidence, nil
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/client"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVolatilityParamsInvalid validates against invalid query params
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message is returned in the JSON body
+// 	- StatusCode of 400 is returned
+func TestVolatilityParamsInvalid(t *testing.T) {
+	_, _, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description string
+		query       string
+		expJSON     string
+	}
+
+	cases := []testParams{
+		{
+			description: "currency is missing",
+			query:       "window=30",
+			expJSON:     `{"error":"invalid query parameter - currency must be provided"}`,
+		},
+		{
+			description: "confidence out of range",
+			query:       "currency=USD&confidence=95",
+			expJSON:     `{"error":"invalid query parameter - confidence must be between 0 and 1"}`,
+		},
+		{
+			description: "confidence is not a number",
+			query:       "currency=USD&confidence=NaN",
+			expJSON:     `{"error":"invalid query parameter - confidence must be between 0 and 1"}`,
+		},
+		{
+			description: "malformed date",
+			query:       "currency=USD&date=22-11-2019",
+			expJSON:     `{"error":"invalid query parameter - date must be YYYY-MM-DD and not in the future"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			volatilityResp := &model.VolatilityResp{}
+			url := "http://localhost:3000/volatility?" + tt.query
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, volatilityResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
+
+// TestVolatilityNoError shows the happy path
+// Scenario:
+// 	- mockFX returns the historical rates of the window ending on the date
+// 	- confidence of 0.8
+//
+// Expect:
+// 	- risk metrics of the rates are returned
+// 	- StatusCode of 200 is returned
+func TestVolatilityNoError(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	mockHistoricalRates := &model.HistoricalRates{
+		RatesList: model.RatesList{
+			"2019-11-01": model.Rates{"EUR": 1.10},
+			"2019-11-02": model.Rates{"EUR": 1.12},
+			"2019-11-03": model.Rates{"EUR": 1.11},
+			"2019-11-04": model.Rates{"EUR": 1.13},
+			"2019-11-05": model.Rates{"EUR": 1.15},
+			"2019-11-06": model.Rates{"EUR": 1.14},
+			"2019-11-07": model.Rates{"EUR": 1.16},
+			"2019-11-08": model.Rates{"EUR": 1.15},
+		},
+		Base:      "USD",
+		StartDate: "2019-11-01",
+		EndDate:   "2019-11-08",
+	}
+
+	mockFX.EXPECT().GetHistoricalRates("USD", "2019-11-01", "2019-11-08").
+		Return(mockHistoricalRates, nil)
+
+	volatilityResp := &model.VolatilityResp{}
+	url := "http://localhost:3000/volatility?currency=USD&window=7&date=2019-11-08&confidence=0.8"
+	httpClient := client.NewHTTPClient(client.DefaultSettings())
+	resp, err := httpClient.GET(url, volatilityResp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "USD", volatilityResp.From)
+	assert.Equal(t, "EUR", volatilityResp.To)
+	assert.Equal(t, "2019-11-01", volatilityResp.Start)
+	assert.Equal(t, "2019-11-08", volatilityResp.End)
+
+	risk := volatilityResp.Risk
+	assert.NotNil(t, risk)
+	assert.Equal(t, 7, risk.Returns)
+	assert.InDelta(t, 0.014161173020295766, risk.Volatility, 1e-12, "volatility is wrong")
+	assert.InDelta(t, 0.2248016525079602, risk.AnnualisedVolatility, 1e-12, "annualised volatility is wrong")
+	assert.InDelta(t, 0.008928571428571435, risk.MaxDrawdown, 1e-12, "max drawdown is wrong")
+	assert.InDelta(t, 0.008695652173913104, risk.ValueAtRisk, 1e-12, "value at risk is wrong")
+	assert.Equal(t, 0.8, risk.Confidence)
+}
+
+// TestVolatilityErrors checks errors getting the rates
+// and measuring the volatility are returned
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- right error message and StatusCode are returned
+func TestVolatilityErrors(t *testing.T) {
+	_, mockFX, xeService, ctrl := setupTestServer(t)
+	defer ctrl.Finish()
+
+	runTestServer(t, xeService)
+	defer xeService.Stop()
+
+	type testParams struct {
+		description     string
+		historicalRates *model.HistoricalRates
+		err             error
+		expStatus       int
+		expJSON         string
+	}
+
+	cases := []testParams{
+		{
+			description: "GetHistoricalRates errors",
+			err:         errors.New("error getting historical rate"),
+			expStatus:   http.StatusInternalServerError,
+			expJSON:     `{"error":"error measuring volatility"}`,
+		},
+		{
+			description: "too few rates",
+			historicalRates: &model.HistoricalRates{
+				RatesList: model.RatesList{
+					"2019-11-07": model.Rates{"EUR": 1.16},
+					"2019-11-08": model.Rates{"EUR": 1.15},
+				},
+			},
+			expStatus: http.StatusUnprocessableEntity,
+			expJSON:   `{"error":"insufficient data - too few rates in the window to measure volatility"}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			mockFX.EXPECT().GetHistoricalRates(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.historicalRates, tt.err)
+
+			volatilityResp := &model.VolatilityResp{}
+			url := "http://localhost:3000/volatility?currency=USD"
+			httpClient := client.NewHTTPClient(client.DefaultSettings())
+			resp, err := httpClient.GET(url, volatilityResp)
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.expStatus, resp.StatusCode())
+			assert.Equal(t, tt.expJSON, string(resp.Body()))
+		})
+	}
+}
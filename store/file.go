@@ -0,0 +1,150 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jeffreyyong/xe/calculator"
+)
+
+// ErrClosed is returned when saving to a closed store
+var ErrClosed = errors.New("store closed")
+
+// File is a calculator.SignalStore persisting the signals
+// as a JSON object of key:daily signal in a file, so they
+// survive restarts
+type File struct {
+	mu      sync.Mutex
+	path    string
+	signals map[string]calculator.DailySignal
+	closed  bool
+}
+
+// NewFile initialises a File store, loading the
+// signals saved in the file at path if it exists.
+// A file of key:signal written by earlier versions
+// is loaded as signals saved before any day.
+func NewFile(path string) (*File, error) {
+	f := &File{
+		path:    path,
+		signals: map[string]calculator.DailySignal{},
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &f.signals); err == nil {
+		return f, nil
+	}
+
+	legacy := map[string]calculator.Signal{}
+	if err := json.Unmarshal(b, &legacy); err != nil {
+		return nil, fmt.Errorf("decoding signals in %s: %v", path, err)
+	}
+	for key, signal := range legacy {
+		f.signals[key] = calculator.DailySignal{Signal: signal}
+	}
+	return f, nil
+}
+
+// Last returns the last signal saved under the key
+func (f *File) Last(key string) (calculator.Signal, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	signal, ok := f.signals[key]
+	return signal.Signal, ok, nil
+}
+
+// Update saves the signal fn returns from the signal carried
+// into the day under the key and writes the signals to the
+// file, the signal is kept only if the file is written
+func (f *File) Update(key, day string, fn func(previous calculator.Signal) calculator.Signal) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrClosed
+	}
+	current, ok := f.signals[key]
+	next := current.Advance(day, fn)
+	if ok && next == current {
+		return nil
+	}
+	f.signals[key] = next
+
+	b, err := json.MarshalIndent(f.signals, "", "  ")
+	if err == nil {
+		err = writeFile(f.path, b)
+	}
+	if err != nil {
+		if ok {
+			f.signals[key] = current
+		} else {
+			delete(f.signals, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Close closes the store once the save in progress is written,
+// the signals are written on every save so none are lost
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+// Check returns an error if the store is closed
+// or the directory of its file is missing
+func (f *File) Check() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrClosed
+	}
+	return checkDir(f.path)
+}
+
+// checkDir returns an error if the directory
+// files are written to at path is missing
+func checkDir(path string) error {
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", filepath.Dir(path))
+	}
+	return nil
+}
+
+// writeFile writes b to a temp file renamed to
+// path, so the file is never partially written
+func writeFile(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
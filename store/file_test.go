@@ -0,0 +1,106 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFile checks the signals survive
+// reopening the file
+// Scenario:
+// 	- signals are saved in a new file
+// 	- the file is reopened
+//
+// Expect:
+// 	- the last signal per key is loaded
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signals.json")
+
+	f, err := NewFile(path)
+	assert.NoError(t, err)
+	_, ok, err := f.Last("USD/EUR/trend")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, f.Update("USD/EUR/trend", "2019-11-21", signalOf(calculator.SignalConvert)))
+	assert.NoError(t, f.Update("USD/EUR/trend", "2019-11-22", signalOf(calculator.SignalNoConvert)))
+	assert.NoError(t, f.Update("GBP/EUR/rsi", "2019-11-22", signalOf(calculator.SignalNeutral)))
+
+	reopened, err := NewFile(path)
+	assert.NoError(t, err)
+
+	signal, ok, err := reopened.Last("USD/EUR/trend")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, calculator.SignalNoConvert, signal)
+
+	var previous calculator.Signal
+	assert.NoError(t, reopened.Update("USD/EUR/trend", "2019-11-22", func(p calculator.Signal) calculator.Signal {
+		previous = p
+		return p
+	}))
+	assert.Equal(t, calculator.SignalConvert, previous, "signal carried into the day is wrong")
+
+	signal, _, _ = reopened.Last("GBP/EUR/rsi")
+	assert.Equal(t, calculator.SignalNeutral, signal)
+
+	assert.NoError(t, f.Check())
+	missing, err := NewFile(filepath.Join(dir, "missing", "signals.json"))
+	assert.NoError(t, err)
+	assert.Error(t, missing.Check())
+
+	assert.NoError(t, f.Close())
+	assert.Equal(t, ErrClosed, f.Check())
+	assert.Equal(t, ErrClosed, f.Update("USD/EUR/trend", "2019-11-23", signalOf(calculator.SignalConvert)))
+	signal, _, _ = f.Last("USD/EUR/trend")
+	assert.Equal(t, calculator.SignalNoConvert, signal)
+}
+
+// TestNewFileLegacy checks a file of key:signal
+// is loaded as signals saved before any day
+func TestNewFileLegacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signals.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"USD/EUR/trend":"convert"}`), 0644))
+
+	f, err := NewFile(path)
+	assert.NoError(t, err)
+
+	var previous calculator.Signal
+	assert.NoError(t, f.Update("USD/EUR/trend", "2019-11-22", func(p calculator.Signal) calculator.Signal {
+		previous = p
+		return calculator.SignalNeutral
+	}))
+	assert.Equal(t, calculator.SignalConvert, previous)
+}
+
+// signalOf returns an update which
+// saves the signal regardless
+func signalOf(signal calculator.Signal) func(calculator.Signal) calculator.Signal {
+	return func(calculator.Signal) calculator.Signal {
+		return signal
+	}
+}
+
+// TestNewFileInvalid checks a file
+// which is not JSON is rejected
+func TestNewFileInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signals.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("convert"), 0644))
+
+	_, err = NewFile(path)
+	assert.Error(t, err)
+}
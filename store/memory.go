@@ -0,0 +1,40 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/jeffreyyong/xe/calculator"
+)
+
+// Memory is a calculator.SignalStore holding the
+// signals in memory, they are lost on restart
+type Memory struct {
+	mu      sync.Mutex
+	signals map[string]calculator.DailySignal
+}
+
+// NewMemory initialises an empty Memory store
+func NewMemory() *Memory {
+	return &Memory{
+		signals: map[string]calculator.DailySignal{},
+	}
+}
+
+// Last returns the last signal saved under the key
+func (m *Memory) Last(key string) (calculator.Signal, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	signal, ok := m.signals[key]
+	return signal.Signal, ok, nil
+}
+
+// Update saves the signal fn returns from the signal
+// carried into the day under the key
+func (m *Memory) Update(key, day string, fn func(previous calculator.Signal) calculator.Signal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.signals[key] = m.signals[key].Advance(day, fn)
+	return nil
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemory checks the last signal is returned
+// per key and advances at most once a day
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+
+	_, ok, err := m.Last("USD/EUR/trend")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	var previous []calculator.Signal
+	update := func(key, day string, signal calculator.Signal) {
+		assert.NoError(t, m.Update(key, day, func(p calculator.Signal) calculator.Signal {
+			previous = append(previous, p)
+			return signal
+		}))
+	}
+	update("USD/EUR/trend", "2019-11-21", calculator.SignalConvert)
+	update("USD/EUR/trend", "2019-11-22", calculator.SignalNoConvert)
+	update("USD/EUR/trend", "2019-11-22", calculator.SignalNeutral)
+	update("GBP/EUR/trend", "2019-11-22", calculator.SignalNoConvert)
+
+	assert.Equal(t, []calculator.Signal{"", calculator.SignalConvert, calculator.SignalConvert, ""}, previous)
+
+	signal, ok, err := m.Last("USD/EUR/trend")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, calculator.SignalNeutral, signal)
+
+	signal, _, _ = m.Last("GBP/EUR/trend")
+	assert.Equal(t, calculator.SignalNoConvert, signal)
+}
@@ -0,0 +1,350 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/jeffreyyong/xe/tracking"
+)
+
+// DefaultOutcomeLimit is the default number
+// of scored outcomes kept
+const DefaultOutcomeLimit = 100000
+
+// Outcomes is a tracking.Store holding the outcomes in memory,
+// persisted in a file if opened with a path. The file is a log
+// of the outcomes as JSON lines: every Add and Update appends
+// the outcome, a later line replacing an earlier one with the
+// same ID, and the log is compacted when the file is opened.
+// Only the latest scored outcomes are kept, up to the limit,
+// unscored outcomes are always kept.
+type Outcomes struct {
+	mu    sync.Mutex
+	path  string
+	log   *os.File
+	limit int
+
+	// outcomes are sorted by ID, next is
+	// the ID of the next outcome added
+	outcomes []tracking.Outcome
+	next     int
+	scored   int
+
+	served map[servedKey]int
+	closed bool
+}
+
+// servedKey identifies the recommendation of a
+// strategy for a currency over a window on a date
+type servedKey struct {
+	currency, strategy string
+	window             int
+	date               string
+}
+
+// NewOutcomes initialises an empty Outcomes store keeping
+// up to limit scored outcomes, or all of them if limit is 0.
+// The outcomes are lost on restart.
+func NewOutcomes(limit int) *Outcomes {
+	return &Outcomes{
+		limit:  limit,
+		next:   1,
+		served: map[servedKey]int{},
+	}
+}
+
+// OpenOutcomes initialises an Outcomes store persisted in the
+// file at path, loading the outcomes saved in it if it exists.
+// A file of a JSON array written by earlier versions is loaded
+// and rewritten as a log.
+func OpenOutcomes(path string, limit int) (*Outcomes, error) {
+	o := NewOutcomes(limit)
+	o.path = path
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	outcomes, err := decodeOutcomes(b)
+	if err != nil {
+		return nil, fmt.Errorf("decoding outcomes in %s: %v", path, err)
+	}
+	for _, outcome := range outcomes {
+		o.outcomes = append(o.outcomes, outcome)
+		o.served[keyOf(outcome)] = outcome.ID
+		o.next = outcome.ID + 1
+		if outcome.Scored {
+			o.scored++
+		}
+	}
+	o.drop()
+
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Add saves a new outcome with the next ID. The recommendation
+// of a strategy for a currency over a window is tracked once a
+// date, so an outcome of the same currency, strategy, window and
+// date replaces the unscored one saved before and keeps its ID.
+func (o *Outcomes) Add(outcome tracking.Outcome) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return ErrClosed
+	}
+
+	key := keyOf(outcome)
+	if id, ok := o.served[key]; ok {
+		i := o.index(id)
+		if o.outcomes[i].Scored {
+			return nil
+		}
+		outcome.ID = id
+		return o.save(i, outcome)
+	}
+
+	outcome.ID = o.next
+	if err := o.append(outcome); err != nil {
+		return err
+	}
+	o.outcomes = append(o.outcomes, outcome)
+	o.served[key] = outcome.ID
+	o.next++
+	return nil
+}
+
+// Update saves the outcome with the same ID
+func (o *Outcomes) Update(outcome tracking.Outcome) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return ErrClosed
+	}
+	i := o.index(outcome.ID)
+	if i < 0 {
+		return fmt.Errorf("unknown outcome: %d", outcome.ID)
+	}
+	return o.save(i, outcome)
+}
+
+// Unscored returns the outcomes which are not
+// scored and are due on or before the date
+func (o *Outcomes) Unscored(due string) ([]tracking.Outcome, error) {
+	return o.filter(func(outcome tracking.Outcome) bool {
+		return !outcome.Scored && outcome.Due <= due
+	}), nil
+}
+
+// List returns the outcomes of the strategy
+func (o *Outcomes) List(strategy string) ([]tracking.Outcome, error) {
+	return o.filter(func(outcome tracking.Outcome) bool {
+		return outcome.Strategy == strategy
+	}), nil
+}
+
+// filter returns the outcomes matching keep
+func (o *Outcomes) filter(keep func(tracking.Outcome) bool) []tracking.Outcome {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var outcomes []tracking.Outcome
+	for _, outcome := range o.outcomes {
+		if keep(outcome) {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+// Close closes the store once the save in progress is written,
+// the outcomes are written on every save so none are lost
+func (o *Outcomes) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	if o.log == nil {
+		return nil
+	}
+	return o.log.Close()
+}
+
+// Check returns an error if the store is closed or
+// the directory of its file, if it has one, is missing
+func (o *Outcomes) Check() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrClosed
+	}
+	if o.path == "" {
+		return nil
+	}
+	return checkDir(o.path)
+}
+
+// save replaces the outcome at index i, which has the same ID,
+// once it is appended to the log, the caller holds the lock
+func (o *Outcomes) save(i int, outcome tracking.Outcome) error {
+	if err := o.append(outcome); err != nil {
+		return err
+	}
+	if outcome.Scored && !o.outcomes[i].Scored {
+		o.scored++
+	}
+	o.outcomes[i] = outcome
+	o.drop()
+	return nil
+}
+
+// index returns the index of the outcome with the ID, -1
+// if there is none, the caller holds the lock
+func (o *Outcomes) index(id int) int {
+	i := sort.Search(len(o.outcomes), func(i int) bool {
+		return o.outcomes[i].ID >= id
+	})
+	if i == len(o.outcomes) || o.outcomes[i].ID != id {
+		return -1
+	}
+	return i
+}
+
+// drop drops the oldest scored outcomes and their keys beyond
+// the limit, the log keeps them until it is compacted. The
+// caller holds the lock.
+func (o *Outcomes) drop() {
+	if o.limit < 1 || o.scored <= o.limit {
+		return
+	}
+
+	excess := o.scored - o.limit
+	kept := o.outcomes[:0]
+	for _, outcome := range o.outcomes {
+		if outcome.Scored && excess > 0 {
+			delete(o.served, keyOf(outcome))
+			excess--
+			o.scored--
+			continue
+		}
+		kept = append(kept, outcome)
+	}
+	o.outcomes = kept
+}
+
+// append appends the outcome to the log if the
+// store has a file, the caller holds the lock
+func (o *Outcomes) append(outcome tracking.Outcome) error {
+	if o.log == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(outcome)
+	if err != nil {
+		return err
+	}
+	_, err = o.log.Write(append(b, '\n'))
+	return err
+}
+
+// compact rewrites the log with the latest line of every
+// outcome and opens it to append to, if the store has a file
+func (o *Outcomes) compact() error {
+	if o.path == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, outcome := range o.outcomes {
+		b, err := json.Marshal(outcome)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(b, '\n'))
+	}
+	if err := writeFile(o.path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(o.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	o.log = f
+	return nil
+}
+
+// decodeOutcomes decodes the outcomes of the log in b, or of
+// a JSON array written by earlier versions. A last line cut
+// short by a crash is dropped. The IDs must be positive and
+// increasing, they skip the outcomes dropped beyond the limit.
+func decodeOutcomes(b []byte) ([]tracking.Outcome, error) {
+	var outcomes []tracking.Outcome
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &outcomes); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if outcomes, err = decodeLog(b); err != nil {
+			return nil, err
+		}
+	}
+
+	last := 0
+	for i, outcome := range outcomes {
+		if outcome.ID <= last {
+			return nil, fmt.Errorf("outcome %d has ID %d", i+1, outcome.ID)
+		}
+		last = outcome.ID
+	}
+	return outcomes, nil
+}
+
+// decodeLog replays the lines of the log in b, a line
+// replaces the outcome with the same ID if there is one
+func decodeLog(b []byte) ([]tracking.Outcome, error) {
+	var outcomes []tracking.Outcome
+	indexes := map[int]int{}
+	lines := bytes.Split(b, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var outcome tracking.Outcome
+		if err := json.Unmarshal(line, &outcome); err != nil {
+			// only the last line lacks the newline
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		if j, ok := indexes[outcome.ID]; ok {
+			outcomes[j] = outcome
+		} else {
+			indexes[outcome.ID] = len(outcomes)
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes, nil
+}
+
+// keyOf returns the key of the
+// recommendation of the outcome
+func keyOf(outcome tracking.Outcome) servedKey {
+	return servedKey{outcome.Currency, outcome.Strategy, outcome.Window, outcome.Date}
+}
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/tracking"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOutcomes checks the outcomes are saved,
+// updated and filtered
+func TestOutcomes(t *testing.T) {
+	o := NewOutcomes(DefaultOutcomeLimit)
+	assert.NoError(t, o.Add(tracking.Outcome{Strategy: "trend", Signal: calculator.SignalConvert, Due: "2019-11-08"}))
+	assert.NoError(t, o.Add(tracking.Outcome{Strategy: "rsi", Signal: calculator.SignalConvert, Due: "2019-11-09"}))
+
+	unscored, err := o.Unscored("2019-11-08")
+	assert.NoError(t, err)
+	assert.Len(t, unscored, 1)
+	assert.Equal(t, 1, unscored[0].ID)
+
+	unscored[0].Scored = true
+	assert.NoError(t, o.Update(unscored[0]))
+	assert.Error(t, o.Update(tracking.Outcome{ID: 3}))
+
+	unscored, err = o.Unscored("2019-11-09")
+	assert.NoError(t, err)
+	assert.Len(t, unscored, 1)
+	assert.Equal(t, "rsi", unscored[0].Strategy)
+
+	trend, err := o.List("trend")
+	assert.NoError(t, err)
+	assert.Len(t, trend, 1)
+	assert.True(t, trend[0].Scored)
+
+	assert.NoError(t, o.Check())
+	assert.NoError(t, o.Close())
+	assert.Equal(t, ErrClosed, o.Check())
+	assert.Equal(t, ErrClosed, o.Add(tracking.Outcome{Strategy: "trend"}))
+	assert.Equal(t, ErrClosed, o.Update(trend[0]))
+	trend, err = o.List("trend")
+	assert.NoError(t, err)
+	assert.Len(t, trend, 1)
+}
+
+// TestOpenOutcomes checks the outcomes
+// survive reopening the file
+// Scenario:
+// 	- outcomes are saved in a new file
+// 	- the file is reopened
+//
+// Expect:
+// 	- the outcomes are loaded and new ones get the next ID
+func TestOpenOutcomes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outcomes.json")
+
+	o, err := OpenOutcomes(path, DefaultOutcomeLimit)
+	assert.NoError(t, err)
+	assert.NoError(t, o.Add(tracking.Outcome{Strategy: "trend", Signal: calculator.SignalConvert, Date: "2019-11-01", Rate: 1.1}))
+	assert.NoError(t, o.Add(tracking.Outcome{Strategy: "trend", Signal: calculator.SignalConvert, Date: "2019-11-04"}))
+	assert.NoError(t, o.Update(tracking.Outcome{ID: 2, Strategy: "trend", Signal: calculator.SignalConvert, Date: "2019-11-04",
+		Scored: true}))
+	assert.NoError(t, o.Close())
+
+	reopened, err := OpenOutcomes(path, DefaultOutcomeLimit)
+	assert.NoError(t, err)
+	assert.NoError(t, reopened.Add(tracking.Outcome{Strategy: "trend", Signal: calculator.SignalNeutral, Date: "2019-11-05"}))
+
+	outcomes, err := reopened.List("trend")
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, 3)
+	assert.Equal(t, 1.1, outcomes[0].Rate)
+	assert.True(t, outcomes[1].Scored)
+	assert.Equal(t, 3, outcomes[2].ID)
+	assert.NoError(t, reopened.Close())
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, bytes.Count(b, []byte("\n")), "log is not compacted on open")
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{}"), 0644))
+	_, err = OpenOutcomes(path, DefaultOutcomeLimit)
+	assert.Error(t, err)
+}
+
+// TestOutcomesSameDay checks the recommendation of a strategy
+// for a currency over a window is tracked once a date
+// Scenario:
+// 	- 2 recommendations of the trend strategy for USD on a date
+// 	- 1 recommendation for GBP, 1 over another window and
+// 	  1 on the next date
+// 	- a recommendation on the date of a scored outcome
+//
+// Expect:
+// 	- the second recommendation on a date replaces the first
+// 	- the scored outcome is kept
+func TestOutcomesSameDay(t *testing.T) {
+	o := NewOutcomes(DefaultOutcomeLimit)
+	assert.NoError(t, o.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Date: "2019-11-01",
+		Signal: calculator.SignalConvert, Rate: 1.1}))
+	assert.NoError(t, o.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Date: "2019-11-01",
+		Signal: calculator.SignalNeutral, Rate: 1.2}))
+	assert.NoError(t, o.Add(tracking.Outcome{Currency: "GBP", Strategy: "trend", Date: "2019-11-01"}))
+	assert.NoError(t, o.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Window: 30, Date: "2019-11-01"}))
+	assert.NoError(t, o.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Date: "2019-11-04"}))
+
+	outcomes, err := o.List("trend")
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, 4)
+	assert.Equal(t, tracking.Outcome{ID: 1, Currency: "USD", Strategy: "trend", Date: "2019-11-01",
+		Signal: calculator.SignalNeutral, Rate: 1.2}, outcomes[0])
+
+	outcomes[0].Scored = true
+	assert.NoError(t, o.Update(outcomes[0]))
+	assert.NoError(t, o.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Date: "2019-11-01",
+		Signal: calculator.SignalConvert}))
+	outcomes, err = o.List("trend")
+	assert.NoError(t, err)
+	assert.Equal(t, calculator.SignalNeutral, outcomes[0].Signal)
+}
+
+// TestOutcomesLimit checks only the latest
+// scored outcomes are kept up to the limit
+// Scenario:
+// 	- 4 outcomes are saved in a file store with a limit of 2
+// 	- 3 of them are scored, the last one is not
+// 	- the file is reopened and an outcome is added
+//
+// Expect:
+// 	- the oldest scored outcome is dropped with its key,
+// 	  so a recommendation on its date is tracked again
+// 	- the unscored outcome is kept
+// 	- the dropped outcome is not loaded and the IDs go on
+func TestOutcomesLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outcomes.json")
+
+	o, err := OpenOutcomes(path, 2)
+	assert.NoError(t, err)
+	days := []string{"2019-11-01", "2019-11-04", "2019-11-05", "2019-11-06"}
+	for _, day := range days {
+		assert.NoError(t, o.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Date: day}))
+	}
+	for i, day := range days[:3] {
+		assert.NoError(t, o.Update(tracking.Outcome{ID: i + 1, Currency: "USD", Strategy: "trend", Date: day,
+			Scored: true}))
+	}
+
+	outcomes, err := o.List("trend")
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, 3)
+	assert.Equal(t, 2, outcomes[0].ID)
+	assert.False(t, outcomes[2].Scored)
+	assert.Error(t, o.Update(tracking.Outcome{ID: 1}))
+	assert.NoError(t, o.Close())
+
+	reopened, err := OpenOutcomes(path, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, reopened.Add(tracking.Outcome{Currency: "USD", Strategy: "trend", Date: "2019-11-01"}))
+
+	outcomes, err = reopened.List("trend")
+	assert.NoError(t, err)
+	var ids []int
+	for _, outcome := range outcomes {
+		ids = append(ids, outcome.ID)
+	}
+	assert.Equal(t, []int{2, 3, 4, 5}, ids)
+	assert.NoError(t, reopened.Close())
+}
+
+// TestOpenOutcomesLog checks the outcomes are loaded from
+// the log and from a JSON array written by earlier versions
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- the outcomes are loaded or the right error is returned
+func TestOpenOutcomesLog(t *testing.T) {
+	type testParams struct {
+		description string
+		content     string
+		expSignals  []calculator.Signal
+		expErr      bool
+	}
+
+	cases := []testParams{
+		{
+			description: "a later line replaces the outcome with the same ID",
+			content:     `{"ID":1,"Signal":"convert"}` + "\n" + `{"ID":2,"Signal":"neutral"}` + "\n" + `{"ID":1,"Signal":"don't convert"}` + "\n",
+			expSignals:  []calculator.Signal{calculator.SignalNoConvert, calculator.SignalNeutral},
+		},
+		{
+			description: "a last line cut short is dropped",
+			content:     `{"ID":1,"Signal":"convert"}` + "\n" + `{"ID":2,"Sig`,
+			expSignals:  []calculator.Signal{calculator.SignalConvert},
+		},
+		{
+			description: "a JSON array is loaded",
+			content:     `[{"ID":1,"Signal":"convert"},{"ID":2,"Signal":"neutral"}]`,
+			expSignals:  []calculator.Signal{calculator.SignalConvert, calculator.SignalNeutral},
+		},
+		{
+			description: "error if a line in the middle is invalid",
+			content:     `{"ID":1,"Signal":"convert"}` + "\n" + `{"ID":2,"Sig` + "\n" + `{"ID":2,"Signal":"neutral"}` + "\n",
+			expErr:      true,
+		},
+		{
+			description: "IDs of dropped outcomes are skipped",
+			content:     `{"ID":2,"Signal":"convert"}` + "\n" + `{"ID":4,"Signal":"neutral"}` + "\n",
+			expSignals:  []calculator.Signal{calculator.SignalConvert, calculator.SignalNeutral},
+		},
+		{
+			description: "error if the IDs are not increasing",
+			content:     `{"ID":2,"Signal":"convert"}` + "\n" + `{"ID":1,"Signal":"neutral"}` + "\n",
+			expErr:      true,
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for i, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("outcomes%d.json", i))
+			assert.NoError(t, ioutil.WriteFile(path, []byte(tt.content), 0644))
+
+			o, err := OpenOutcomes(path, DefaultOutcomeLimit)
+			if tt.expErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			defer o.Close()
+
+			outcomes, err := o.List("")
+			assert.NoError(t, err)
+			var signals []calculator.Signal
+			for _, outcome := range outcomes {
+				signals = append(signals, outcome.Signal)
+			}
+			assert.Equal(t, tt.expSignals, signals)
+		})
+	}
+}
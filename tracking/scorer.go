@@ -0,0 +1,103 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/client"
+)
+
+// DefaultScoreEvery is the default interval
+// between two scorings of the due outcomes
+const DefaultScoreEvery = time.Hour
+
+var errNoRate = errors.New("no EUR rate")
+
+// Scorer scores the due outcomes of the
+// Tracker in the background
+type Scorer struct {
+	tracker *Tracker
+	fx      client.Forex
+	every   time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewScorer initialises a Scorer scoring the outcomes of the
+// tracker every interval with the rates of the forex client
+func NewScorer(tracker *Tracker, fx client.Forex, every time.Duration) (*Scorer, error) {
+	if every <= 0 {
+		return nil, fmt.Errorf("scoring interval must be positive, got %v", every)
+	}
+
+	return &Scorer{
+		tracker: tracker,
+		fx:      fx,
+		every:   every,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Run scores the due outcomes now and then every
+// interval until Stop is called
+func (s *Scorer) Run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.every)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Score(time.Now()); err != nil {
+			log.Print("scoring outcomes: ", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Stop stops Run and waits for the scoring in progress to
+// finish, or for ctx to be done so a slow scoring does not
+// hold up the shutdown, in which case it returns the error
+// of ctx and the scoring finishes in the background
+func (s *Scorer) Stop(ctx context.Context) error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Score scores the outcomes due before today
+// with the EUR rate published on their due date
+func (s *Scorer) Score(today time.Time) (int, error) {
+	return s.tracker.ScoreDue(today, func(currency, day string) (float64, error) {
+		rate, err := s.fx.GetHistoricalRate(currency, day)
+		if err != nil {
+			return 0, err
+		}
+		if rate == nil {
+			return 0, errNoRate
+		}
+
+		eur, ok := rate.Rates[calculator.EUR]
+		if !ok {
+			return 0, errNoRate
+		}
+		return eur, nil
+	})
+}
@@ -0,0 +1,133 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jeffreyyong/xe/calculator"
+	forexmock "github.com/jeffreyyong/xe/client/mock"
+	"github.com/jeffreyyong/xe/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScorerScore checks the due outcomes are
+// scored with the rate of their due date
+// Scenario:
+// 	- a convert recommendation on 2019-11-01 at 1.0
+// 	- mockFX returns 0.9 for 2019-11-08
+//
+// Expect:
+// 	- the outcome is scored as a hit
+func TestScorerScore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := forexmock.NewMockForex(ctrl)
+
+	s := &memStore{}
+	tracker, err := NewTracker(s, DefaultHorizon)
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.Record("USD", "trend", 7, calculator.SignalConvert, "2019-11-01", 1))
+
+	mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-08").
+		Return(&model.LatestRate{Rates: model.Rates{"EUR": 0.9}, Base: "USD", Date: "2019-11-08"}, nil)
+
+	scorer, err := NewScorer(tracker, mockFX, DefaultScoreEvery)
+	assert.NoError(t, err)
+	scored, err := scorer.Score(time.Date(2019, 11, 10, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, scored)
+	assert.True(t, s.outcomes[0].Scored)
+	assert.True(t, s.outcomes[0].Hit)
+	assert.Equal(t, 0.9, s.outcomes[0].LaterRate)
+}
+
+// TestScorerScoreError checks the outcome stays
+// unscored if the rate can't be retrieved, the
+// error is logged and not returned
+func TestScorerScoreError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := forexmock.NewMockForex(ctrl)
+
+	s := &memStore{}
+	tracker, err := NewTracker(s, DefaultHorizon)
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.Record("USD", "trend", 7, calculator.SignalConvert, "2019-11-01", 1))
+	scorer, err := NewScorer(tracker, mockFX, DefaultScoreEvery)
+	assert.NoError(t, err)
+	today := time.Date(2019, 11, 10, 0, 0, 0, 0, time.UTC)
+
+	mockFX.EXPECT().GetHistoricalRate(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error getting rate"))
+	scored, err := scorer.Score(today)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, scored)
+
+	mockFX.EXPECT().GetHistoricalRate(gomock.Any(), gomock.Any()).
+		Return(&model.LatestRate{Rates: model.Rates{"GBP": 0.9}}, nil)
+	scored, err = scorer.Score(today)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, scored)
+	assert.False(t, s.outcomes[0].Scored)
+}
+
+// TestScorerRunStop checks Run scores on start
+// and returns once stopped
+func TestScorerRunStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := forexmock.NewMockForex(ctrl)
+
+	tracker, err := NewTracker(&memStore{}, DefaultHorizon)
+	assert.NoError(t, err)
+	scorer, err := NewScorer(tracker, mockFX, time.Hour)
+	assert.NoError(t, err)
+
+	go scorer.Run()
+	assert.NoError(t, scorer.Stop(context.Background()))
+	assert.NoError(t, scorer.Stop(context.Background()))
+
+	_, err = NewScorer(tracker, mockFX, 0)
+	assert.Error(t, err)
+}
+
+// TestScorerStopTimeout checks Stop returns once
+// the context is done while a scoring is slow
+// Scenario:
+// 	- a due outcome is scored with a rate which
+// 	  is not returned until the test ends
+// 	- Stop is called with a context timing out
+//
+// Expect:
+// 	- Stop returns the error of the context
+func TestScorerStopTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFX := forexmock.NewMockForex(ctrl)
+
+	tracker, err := NewTracker(&memStore{}, DefaultHorizon)
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.Record("USD", "trend", 7, calculator.SignalConvert, "2019-11-01", 1))
+	scorer, err := NewScorer(tracker, mockFX, time.Hour)
+	assert.NoError(t, err)
+
+	scoring, release := make(chan struct{}), make(chan struct{})
+	mockFX.EXPECT().GetHistoricalRate("USD", "2019-11-08").
+		DoAndReturn(func(string, string) (*model.LatestRate, error) {
+			close(scoring)
+			<-release
+			return nil, errors.New("error getting rate")
+		})
+
+	go scorer.Run()
+	<-scoring
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, scorer.Stop(ctx))
+
+	close(release)
+	assert.NoError(t, scorer.Stop(context.Background()))
+}
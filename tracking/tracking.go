@@ -0,0 +1,222 @@
+package tracking
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/jeffreyyong/xe/date"
+)
+
+// DefaultHorizon is the default number of calendar days after a
+// recommendation the rate is compared with to score it
+const DefaultHorizon = 7
+
+// Outcome is a recommendation served to a caller
+// and, once scored, whether following it paid off
+type Outcome struct {
+	// ID is assigned by the Store
+	ID int
+
+	Currency string
+	Strategy string
+	Signal   calculator.Signal
+
+	// Window is the number of days of rates
+	// the recommendation is based on
+	Window int
+
+	// Date and Rate are the date and rate the
+	// recommendation was served with
+	Date string
+	Rate float64
+
+	// Due is the date the recommendation is scored on
+	Due string
+
+	// Scored tells if LaterRate, Hit and Gain are known.
+	// LaterRate is the rate on Due, Hit tells if following
+	// the recommendation was better than doing the opposite
+	// and Gain is the gain of following it as a fraction of Rate
+	Scored    bool
+	LaterRate float64
+	Hit       bool
+	Gain      float64
+}
+
+// Store persists the outcomes
+type Store interface {
+	// Add saves a new outcome and assigns its ID, an outcome
+	// of the same currency, strategy, window and date replaces
+	// the unscored one saved before
+	Add(o Outcome) error
+
+	// Update saves the outcome with the same ID
+	Update(o Outcome) error
+
+	// Unscored returns the outcomes which are not scored
+	// and are due on or before the date
+	Unscored(due string) ([]Outcome, error)
+
+	// List returns the outcomes of the strategy
+	List(strategy string) ([]Outcome, error)
+}
+
+// Performance is the accuracy of the recommendations
+// of a strategy whose outcome is known
+type Performance struct {
+	Strategy string
+
+	// Horizon is the number of days after a
+	// recommendation it is scored on
+	Horizon int
+
+	// Recommendations counts every recommendation served,
+	// Neutral the ones without advice to follow and Pending
+	// the ones not scored yet
+	Recommendations int
+	Neutral         int
+	Pending         int
+
+	Scored   int
+	Hits     int
+	Misses   int
+	Accuracy float64
+
+	// AverageGain is the mean gain of following the
+	// scored recommendations as a fraction of the rate
+	AverageGain float64
+}
+
+// Tracker records the recommendations served
+// and reports the performance of the strategies
+type Tracker struct {
+	store   Store
+	horizon int
+}
+
+// NewTracker initialises a Tracker scoring the
+// recommendations horizon days after they are served
+func NewTracker(store Store, horizon int) (*Tracker, error) {
+	if store == nil {
+		return nil, fmt.Errorf("store must be provided")
+	}
+	if horizon < 1 {
+		return nil, fmt.Errorf("horizon must be positive, got %d", horizon)
+	}
+
+	return &Tracker{
+		store:   store,
+		horizon: horizon,
+	}, nil
+}
+
+// Record saves the recommendation of the strategy over the window
+// served for the currency with the rate of the date, recommendations
+// with insufficient data are not recorded
+func (t *Tracker) Record(currency, strategy string, window int, signal calculator.Signal, day string,
+	rate float64) error {
+	if signal == calculator.SignalInsufficientData {
+		return nil
+	}
+
+	d, err := date.Parse(day)
+	if err != nil {
+		return err
+	}
+	return t.store.Add(Outcome{
+		Currency: currency,
+		Strategy: strategy,
+		Signal:   signal,
+		Window:   window,
+		Date:     day,
+		Rate:     rate,
+		Due:      date.Format(d.AddDate(0, 0, t.horizon)),
+	})
+}
+
+// Performance summarises the outcomes of the strategy
+func (t *Tracker) Performance(strategy string) (*Performance, error) {
+	outcomes, err := t.store.List(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Performance{
+		Strategy:        strategy,
+		Horizon:         t.horizon,
+		Recommendations: len(outcomes),
+	}
+	for _, o := range outcomes {
+		switch {
+		case o.Signal == calculator.SignalNeutral:
+			p.Neutral++
+		case !o.Scored:
+			p.Pending++
+		case o.Hit:
+			p.Hits++
+			p.AverageGain += o.Gain
+		default:
+			p.Misses++
+			p.AverageGain += o.Gain
+		}
+	}
+
+	p.Scored = p.Hits + p.Misses
+	if p.Scored > 0 {
+		p.Accuracy = float64(p.Hits) / float64(p.Scored)
+		p.AverageGain /= float64(p.Scored)
+	}
+	return p, nil
+}
+
+// ScoreDue scores the outcomes due before today with the rate
+// of their due date given by rateOn, as the rate of today may
+// not be final yet. Neutral outcomes are marked scored without
+// a hit. A currency whose rate can't be got is logged and its
+// outcomes are left for the next scoring, so it doesn't hold up
+// the other currencies. It returns the number of outcomes scored.
+func (t *Tracker) ScoreDue(today time.Time, rateOn func(currency, day string) (float64, error)) (int, error) {
+	outcomes, err := t.store.Unscored(date.Format(today.AddDate(0, 0, -1)))
+	if err != nil {
+		return 0, err
+	}
+
+	scored := 0
+	failed := map[string]bool{}
+	for _, o := range outcomes {
+		if failed[o.Currency] {
+			continue
+		}
+		later, err := rateOn(o.Currency, o.Due)
+		if err != nil {
+			log.Printf("scoring outcomes of %s: %v", o.Currency, err)
+			failed[o.Currency] = true
+			continue
+		}
+		if err := t.store.Update(score(o, later)); err != nil {
+			return scored, err
+		}
+		scored++
+	}
+	return scored, nil
+}
+
+// score compares the rate the recommendation was served
+// with to the later rate. Converting is a hit if the later
+// rate is not higher, i.e. 1 currency would not have bought
+// more euros by waiting, waiting is a hit if it is higher.
+func score(o Outcome, later float64) Outcome {
+	o.Scored, o.LaterRate = true, later
+
+	switch o.Signal {
+	case calculator.SignalConvert:
+		o.Gain = (o.Rate - later) / o.Rate
+		o.Hit = later <= o.Rate
+	case calculator.SignalNoConvert:
+		o.Gain = (later - o.Rate) / o.Rate
+		o.Hit = later > o.Rate
+	}
+	return o
+}
@@ -0,0 +1,224 @@
+package tracking
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeffreyyong/xe/calculator"
+	"github.com/stretchr/testify/assert"
+)
+
+// memStore is a Store in memory
+type memStore struct {
+	outcomes []Outcome
+}
+
+func (m *memStore) Add(o Outcome) error {
+	for _, saved := range m.outcomes {
+		if saved.Currency == o.Currency && saved.Strategy == o.Strategy && saved.Window == o.Window &&
+			saved.Date == o.Date {
+			if !saved.Scored {
+				o.ID = saved.ID
+				m.outcomes[o.ID-1] = o
+			}
+			return nil
+		}
+	}
+	o.ID = len(m.outcomes) + 1
+	m.outcomes = append(m.outcomes, o)
+	return nil
+}
+
+func (m *memStore) Update(o Outcome) error {
+	m.outcomes[o.ID-1] = o
+	return nil
+}
+
+func (m *memStore) Unscored(due string) ([]Outcome, error) {
+	var outcomes []Outcome
+	for _, o := range m.outcomes {
+		if !o.Scored && o.Due <= due {
+			outcomes = append(outcomes, o)
+		}
+	}
+	return outcomes, nil
+}
+
+func (m *memStore) List(strategy string) ([]Outcome, error) {
+	var outcomes []Outcome
+	for _, o := range m.outcomes {
+		if o.Strategy == strategy {
+			outcomes = append(outcomes, o)
+		}
+	}
+	return outcomes, nil
+}
+
+// TestScore checks that following the
+// recommendation is scored against the later rate
+// Scenario:
+// 	- explained in the descriptions of tests
+//
+// Expect:
+// 	- hit and gain are right
+func TestScore(t *testing.T) {
+	type testParams struct {
+		description string
+		signal      calculator.Signal
+		later       float64
+		expHit      bool
+		expGain     float64
+	}
+
+	cases := []testParams{
+		{
+			description: "convert is a hit if the rate falls",
+			signal:      calculator.SignalConvert,
+			later:       0.9,
+			expHit:      true,
+			expGain:     0.1,
+		},
+		{
+			description: "convert is a miss if the rate rises",
+			signal:      calculator.SignalConvert,
+			later:       1.1,
+			expGain:     -0.1,
+		},
+		{
+			description: "don't convert is a hit if the rate rises",
+			signal:      calculator.SignalNoConvert,
+			later:       1.1,
+			expHit:      true,
+			expGain:     0.1,
+		},
+		{
+			description: "don't convert is a miss if the rate is flat",
+			signal:      calculator.SignalNoConvert,
+			later:       1,
+			expGain:     0,
+		},
+		{
+			description: "neutral is neither",
+			signal:      calculator.SignalNeutral,
+			later:       1.1,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			o := score(Outcome{Signal: tt.signal, Rate: 1}, tt.later)
+			assert.True(t, o.Scored)
+			assert.Equal(t, tt.later, o.LaterRate)
+			assert.Equal(t, tt.expHit, o.Hit, "hit is wrong")
+			assert.InDelta(t, tt.expGain, o.Gain, 1e-12, "gain is wrong")
+		})
+	}
+}
+
+// TestTracker checks the recommendations are
+// recorded, scored when due and summarised
+// Scenario:
+// 	- 4 recommendations of the trend strategy on 2019-11-01 for
+// 	  4 currencies, 1 of the rsi strategy and 1 with insufficient
+// 	  data
+// 	- the rate rises to 1.2 by the due date
+// 	- outcomes are scored on 2019-11-08, then on 2019-11-09
+//
+// Expect:
+// 	- nothing is scored on 2019-11-08 as the rate of the
+// 	  due date may not be final
+// 	- the trend recommendations are scored on 2019-11-09
+// 	- don't convert is a hit, convert a miss and neutral not scored
+func TestTracker(t *testing.T) {
+	s := &memStore{}
+	tracker, err := NewTracker(s, DefaultHorizon)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tracker.Record("USD", "trend", 7, calculator.SignalNoConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("CHF", "trend", 7, calculator.SignalNoConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("JPY", "trend", 7, calculator.SignalConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("CAD", "trend", 7, calculator.SignalNeutral, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("GBP", "rsi", 7, calculator.SignalConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("GBP", "rsi", 7, calculator.SignalInsufficientData, "2019-11-01", 1))
+	assert.Len(t, s.outcomes, 5)
+	assert.Equal(t, "2019-11-08", s.outcomes[0].Due)
+
+	rateOn := func(currency, day string) (float64, error) {
+		assert.Equal(t, "2019-11-08", day)
+		return 1.2, nil
+	}
+	scored, err := tracker.ScoreDue(time.Date(2019, 11, 8, 12, 0, 0, 0, time.UTC), rateOn)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, scored)
+
+	performance, err := tracker.Performance("trend")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, performance.Recommendations)
+	assert.Equal(t, 3, performance.Pending)
+
+	scored, err = tracker.ScoreDue(time.Date(2019, 11, 9, 12, 0, 0, 0, time.UTC), rateOn)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, scored)
+
+	performance, err = tracker.Performance("trend")
+	assert.NoError(t, err)
+	expPerformance := &Performance{
+		Strategy:        "trend",
+		Horizon:         DefaultHorizon,
+		Recommendations: 4,
+		Neutral:         1,
+		Scored:          3,
+		Hits:            2,
+		Misses:          1,
+		Accuracy:        2.0 / 3,
+	}
+	assert.InDelta(t, 0.2/3, performance.AverageGain, 1e-12, "average gain is wrong")
+	performance.AverageGain = 0
+	assert.Equal(t, expPerformance, performance)
+}
+
+// TestTrackerScoreError checks the outcomes of a
+// currency stay unscored if its later rate is unknown
+// Scenario:
+// 	- 2 recommendations for USD and 1 for GBP are due
+// 	- the USD rate can't be got
+//
+// Expect:
+// 	- the USD rate is asked for once and its outcomes stay unscored
+// 	- the GBP outcome is scored
+func TestTrackerScoreError(t *testing.T) {
+	s := &memStore{}
+	tracker, err := NewTracker(s, DefaultHorizon)
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.Record("USD", "trend", 7, calculator.SignalConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("USD", "rsi", 7, calculator.SignalConvert, "2019-11-01", 1))
+	assert.NoError(t, tracker.Record("GBP", "trend", 7, calculator.SignalConvert, "2019-11-01", 1))
+
+	calls := map[string]int{}
+	scored, err := tracker.ScoreDue(time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC), func(currency, _ string) (float64, error) {
+		calls[currency]++
+		if currency == "USD" {
+			return 0, errors.New("error getting rate")
+		}
+		return 1.2, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, scored)
+	assert.Equal(t, map[string]int{"USD": 1, "GBP": 1}, calls)
+	assert.False(t, s.outcomes[0].Scored)
+	assert.False(t, s.outcomes[1].Scored)
+	assert.True(t, s.outcomes[2].Scored)
+
+	assert.Error(t, tracker.Record("USD", "trend", 7, calculator.SignalConvert, "01/11/2019", 1))
+}
+
+// TestNewTrackerInvalid checks the store
+// and horizon are validated
+func TestNewTrackerInvalid(t *testing.T) {
+	_, err := NewTracker(nil, DefaultHorizon)
+	assert.Error(t, err)
+
+	_, err = NewTracker(&memStore{}, 0)
+	assert.Error(t, err)
+}
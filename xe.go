@@ -1,21 +1,290 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
 	"github.com/jeffreyyong/xe/calculator"
 	"github.com/jeffreyyong/xe/client"
+	"github.com/jeffreyyong/xe/config"
+	"github.com/jeffreyyong/xe/experiment"
 	"github.com/jeffreyyong/xe/server"
+	"github.com/jeffreyyong/xe/store"
+	"github.com/jeffreyyong/xe/tracking"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	addr = "localhost:3030"
+	cmdBacktest = "backtest"
+
+	// probeCurrency is the currency of the latest
+	// rate requested to check the api is reachable
+	probeCurrency = "USD"
 )
 
 func main() {
-	c := client.NewHTTPClient()
-	fx := client.NewForex(c)
-	ce := calculator.NewEngine()
-	h := server.NewHandler(fx, ce)
-	httpHandler := server.SetupAPIHandler(h)
-	xeService := server.NewXEService(httpHandler, addr)
-	xeService.Run()
+	if len(os.Args) > 1 && os.Args[1] == cmdBacktest {
+		backtestCmd(os.Args[2:])
+		return
+	}
+
+	loadConfig := config.Flags(flag.CommandLine)
+	flag.Parse()
+
+	load := func() (*config.Config, error) {
+		return loadConfig(os.LookupEnv)
+	}
+	cfg, err := load()
+	if err != nil {
+		log.Fatal("invalid config: ", err)
+	}
+
+	// SIGTERM and SIGINT are caught from now on, so one received
+	// while starting up shuts the service down gracefully once
+	// it serves rather than killing it
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	strategies, forecasters, settings, err := newStrategies(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var signals calculator.SignalStore
+	var stores []io.Closer
+	if cfg.Hysteresis {
+		signalStore, err := newSignalStore(cfg.SignalStore)
+		if err != nil {
+			log.Fatal("invalid signal store: ", err)
+		}
+		signals = signalStore
+		if c, ok := signalStore.(io.Closer); ok {
+			stores = append(stores, c)
+		}
+	}
+
+	outcomes, err := newOutcomeStore(cfg.OutcomeStore, cfg.OutcomeLimit)
+	if err != nil {
+		log.Fatal("invalid outcome store: ", err)
+	}
+	stores = append(stores, outcomes)
+	tracker, err := tracking.NewTracker(outcomes, cfg.OutcomeHorizon)
+	if err != nil {
+		log.Fatal("invalid outcome tracking: ", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	clientMetrics := client.NewMetrics(registry)
+	breakerSettings := cfg.BreakerSettings()
+	var current atomic.Value
+	current.Store(newProvider(cfg, breakerSettings, clientMetrics))
+	fx := client.NewCachedForex(current.Load().(*provider).breaker, cfg.CacheTTLs(), clientMetrics)
+
+	h := server.NewHandler(fx, strategies, forecasters, signals, tracker, settings, server.NewMetrics(registry))
+	scorer, err := tracking.NewScorer(tracker, h.Forex(), cfg.ScoreEvery)
+	if err != nil {
+		log.Fatal("invalid outcome scoring: ", err)
+	}
+	go scorer.Run()
+
+	// reload the settings which do not need a restart on SIGHUP
+	// or change of the config file: the forex client of the
+	// provider and TTLs, the strategies and the settings are
+	// built first and swapped at once in the Handler. A new
+	// provider gets a new client, breaker and empty cache.
+	watcher, err := config.NewWatcher(cfg, load, func(cfg *config.Config) error {
+		strategies, forecasters, settings, err := newStrategies(cfg)
+		if err != nil {
+			return err
+		}
+		p := current.Load().(*provider)
+		if cfg.Provider != p.config {
+			p = newProvider(cfg, breakerSettings, clientMetrics)
+		}
+		fx = fx.With(p.breaker, cfg.CacheTTLs())
+		h.Reload(fx, strategies, forecasters, settings)
+		current.Store(p)
+		return nil
+	})
+	if err != nil {
+		log.Fatal("invalid config watcher: ", err)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go watcher.Run(hup)
+
+	checks := readinessChecks(func() *provider { return current.Load().(*provider) }, cfg.Provider.CheckTTL,
+		signals, outcomes)
+	httpHandler := server.SetupAPIHandler(h, checks...)
+	xeService := server.NewXEService(httpHandler, cfg.Addr, cfg.ShutdownTimeout)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- xeService.Run()
+	}()
+
+	// serve until SIGTERM or SIGINT, then shut down gracefully
+	code := 0
+	select {
+	case sig := <-stop:
+		log.Printf("received %v, shutting down", sig)
+	case err := <-failed:
+		log.Print(err)
+		code = 1
+	}
+	signal.Stop(stop)
+
+	// the scoring gets what is left of the shutdown timeout
+	// once the requests in flight are finished
+	shutdown, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	watcher.Stop()
+	if err := xeService.Stop(); err != nil {
+		log.Print(err)
+		code = 1
+	}
+	if err := scorer.Stop(shutdown); err != nil {
+		log.Print("error stopping the scoring: ", err)
+		code = 1
+	}
+	cancel()
+	for _, s := range stores {
+		if err := s.Close(); err != nil {
+			log.Print("error closing store: ", err)
+			code = 1
+		}
+	}
+	log.Print("XE service stopped")
+	os.Exit(code)
+}
+
+// newStrategies returns the strategies, forecasters
+// and settings of the Handler of the config
+func newStrategies(cfg *config.Config) (*calculator.Registry, calculator.Forecasters, server.Settings, error) {
+	settings := cfg.Settings()
+	p, err := cfg.Strategies.Params()
+	if err != nil {
+		return nil, nil, settings, fmt.Errorf("invalid strategy params: %v", err)
+	}
+	strategies, err := calculator.NewStrategies(p)
+	if err != nil {
+		return nil, nil, settings, fmt.Errorf("invalid strategy params: %v", err)
+	}
+	forecasters, err := calculator.NewForecasters(p)
+	if err != nil {
+		return nil, nil, settings, fmt.Errorf("invalid forecast params: %v", err)
+	}
+	if cfg.Experiment != "" {
+		settings.Experiment, err = loadExperiment(cfg.Experiment, strategies)
+		if err != nil {
+			return nil, nil, settings, fmt.Errorf("invalid experiment: %v", err)
+		}
+	}
+	return strategies, forecasters, settings, nil
+}
+
+// readinessChecks returns the checks of /readyz: the api is
+// reachable, probed at most once per ttl past the circuit
+// breaker so the probe neither trips nor is refused by it,
+// the circuit breaker is not open and the stores are
+// available. current returns the provider the requests are
+// served with.
+func readinessChecks(current func() *provider, ttl time.Duration, signals calculator.SignalStore,
+	outcomes *store.Outcomes) []server.Check {
+	checks := []server.Check{
+		{
+			Name: "provider",
+			Check: server.CachedCheck(func() error {
+				_, err := current().api.GetLatestRate(probeCurrency)
+				return err
+			}, ttl),
+		},
+		{
+			Name: "circuit-breaker",
+			Check: func() error {
+				if current().breaker.State() == client.BreakerOpen {
+					return client.ErrBreakerOpen
+				}
+				return nil
+			},
+		},
+		{Name: "outcome-store", Check: outcomes.Check},
+	}
+	if s, ok := signals.(interface{ Check() error }); ok {
+		checks = append(checks, server.Check{Name: "signal-store", Check: s.Check})
+	}
+	return checks
+}
+
+// newForex initialises the Forex client of the provider
+// of the config, recording its calls in m
+func newForex(cfg *config.Config, m *client.Metrics) client.Forex {
+	return client.NewForex(client.NewInstrumentedHTTPClient(cfg.ClientSettings(), m), cfg.Provider.BaseURL)
+}
+
+// provider is the Forex client of the provider of
+// a config and the circuit breaker in front of it
+type provider struct {
+	config  config.Provider
+	api     client.Forex
+	breaker *client.BreakerForex
+}
+
+// newProvider initialises the Forex client of the provider of the
+// config behind a circuit breaker of the settings, recording its
+// calls in m
+func newProvider(cfg *config.Config, s client.BreakerSettings, m *client.Metrics) *provider {
+	api := newForex(cfg, m)
+	return &provider{
+		config:  cfg.Provider,
+		api:     api,
+		breaker: client.NewBreakerForex(api, s),
+	}
+}
+
+// loadExperiment loads the experiment in the file and
+// checks its arms use known strategies
+func loadExperiment(path string, strategies *calculator.Registry) (*experiment.Experiment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	e, err := experiment.Load(f)
+	if err != nil {
+		return nil, err
+	}
+	known := func(strategy string) bool {
+		_, ok := strategies.Get(strategy)
+		return ok
+	}
+	if err := e.Validate(known); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// newSignalStore opens the file store at path,
+// or a memory store if path is empty
+func newSignalStore(path string) (calculator.SignalStore, error) {
+	if path == "" {
+		return store.NewMemory(), nil
+	}
+	return store.NewFile(path)
+}
+
+// newOutcomeStore opens the outcome store at path keeping up
+// to limit scored outcomes, or a memory store if path is empty
+func newOutcomeStore(path string, limit int) (*store.Outcomes, error) {
+	if path == "" {
+		return store.NewOutcomes(limit), nil
+	}
+	return store.OpenOutcomes(path, limit)
 }